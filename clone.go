@@ -0,0 +1,42 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flatex
+
+import "github.com/starvn/flatex/internal/deepcopy"
+
+// Clone returns a copy of the map that can be changed without affecting the
+// original one. The values are shared, so maps and slices stored as values
+// are still the ones given to the map.
+func (m *Map) Clone() *Map {
+	res := *m
+	res.m = make(map[string]interface{}, len(m.m))
+	for k, v := range m.m {
+		res.m[k] = v
+	}
+	return &res
+}
+
+// DeepClone behaves like Clone, but it also copies the maps and slices
+// stored as values
+func (m *Map) DeepClone() *Map {
+	res := *m
+	res.m = make(map[string]interface{}, len(m.m))
+	for k, v := range m.m {
+		res.m[k] = deepcopy.Copy(v)
+	}
+	return &res
+}
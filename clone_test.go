@@ -0,0 +1,75 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flatex
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMap_Clone(t *testing.T) {
+	in := map[string]interface{}{
+		"a": []interface{}{
+			map[string]interface{}{"b": 1},
+			map[string]interface{}{"b": 2},
+		},
+		"c": map[string]interface{}{"d": []string{"x", "y"}},
+	}
+	m, _ := Flatten(in, DefaultTokenizer)
+	c := m.Clone()
+
+	c.Move("c.d", "c.z")
+	if err := c.RemoveAt("a", 0); err != nil {
+		t.Error(err)
+	}
+
+	if v := m.Expand(); !reflect.DeepEqual(v, in) {
+		t.Errorf("the original map has changed: %v", v)
+	}
+
+	expected := map[string]interface{}{
+		"a": []interface{}{map[string]interface{}{"b": 2}},
+		"c": map[string]interface{}{"z": []string{"x", "y"}},
+	}
+	if v := c.Expand(); !reflect.DeepEqual(v, expected) {
+		t.Errorf("unexpected clone: %v", v)
+	}
+
+	// the values are shared
+	m.m["c.d"].([]string)[0] = "changed"
+	if v := c.m["c.z"]; !reflect.DeepEqual(v, []string{"changed", "y"}) {
+		t.Errorf("unexpected value: %v", v)
+	}
+}
+
+func TestMap_DeepClone(t *testing.T) {
+	m, _ := Flatten(map[string]interface{}{
+		"a": []interface{}{1},
+		"c": map[string]interface{}{"d": []string{"x", "y"}},
+	}, DefaultTokenizer)
+	c := m.DeepClone()
+
+	m.m["c.d"].([]string)[0] = "changed"
+
+	expected := map[string]interface{}{
+		"a": []interface{}{1},
+		"c": map[string]interface{}{"d": []string{"x", "y"}},
+	}
+	if v := c.Expand(); !reflect.DeepEqual(v, expected) {
+		t.Errorf("the clone has changed: %v", v)
+	}
+}
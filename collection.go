@@ -0,0 +1,146 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flatex
+
+import (
+	"errors"
+	"strconv"
+)
+
+var (
+	// ErrNotACollection is returned by the collection operations when the
+	// given prefix does not match any collection
+	ErrNotACollection = errors.New("not a collection")
+	// ErrIndexOutOfRange is returned when the given position is not in the
+	// collection
+	ErrIndexOutOfRange = errors.New("index out of range")
+)
+
+// RemoveAt deletes the element i of every collection matching the prefix,
+// renumbering the elements after it and updating the counter. Negative
+// positions count from the end of the collection.
+func (m *Map) RemoveAt(prefix string, i int) error {
+	cs, err := m.collections(prefix)
+	if err != nil {
+		return err
+	}
+
+	positions := make([]int, len(cs))
+	for j, c := range cs {
+		positions[j] = i
+		if i < 0 {
+			positions[j] += c.size
+		}
+		if positions[j] < 0 || positions[j] >= c.size {
+			return ErrIndexOutOfRange
+		}
+	}
+
+	for j, c := range cs {
+		m.compact(c.prefix, []int{positions[j]})
+	}
+	return nil
+}
+
+// InsertAt flattens the value into every collection matching the prefix, so
+// it ends up as the element i. Negative positions count from the end of the
+// collection.
+func (m *Map) InsertAt(prefix string, i int, value interface{}) error {
+	cs, err := m.collections(prefix)
+	if err != nil {
+		return err
+	}
+
+	positions := make([]int, len(cs))
+	for j, c := range cs {
+		positions[j] = i
+		if i < 0 {
+			positions[j] += c.size
+		}
+		if positions[j] < 0 || positions[j] > c.size {
+			return ErrIndexOutOfRange
+		}
+	}
+
+	for j, c := range cs {
+		m.insertAt(c, positions[j], value)
+	}
+	return nil
+}
+
+// AppendTo flattens the value as the last element of every collection
+// matching the prefix
+func (m *Map) AppendTo(prefix string, value interface{}) error {
+	cs, err := m.collections(prefix)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range cs {
+		m.insertAt(c, c.size, value)
+	}
+	return nil
+}
+
+type collection struct {
+	prefix string
+	size   int
+}
+
+// collections resolves the wildcards, negative indices and slice segments of
+// the prefix and returns the collections it matches, skipping the matches
+// that are not collections. It fails if none of them is a collection.
+func (m *Map) collections(prefix string) ([]collection, error) {
+	ks := m.t.Keys(prefix)
+	var res []collection
+	for _, s := range m.expandSpans(ks, len(ks)) {
+		if size, ok := m.size(s.keys); ok {
+			res = append(res, collection{prefix: m.t.Token(s.keys), size: size})
+		}
+	}
+	if len(res) == 0 {
+		return nil, ErrNotACollection
+	}
+	return res, nil
+}
+
+func (m *Map) insertAt(c collection, i int, value interface{}) {
+	m.shift(c.prefix, i, 1)
+
+	flatten(value, []string{c.prefix, strconv.Itoa(i)}, func(ks []string, v interface{}) {
+		m.m[m.t.Token(ks)] = v
+	})
+	m.m[c.prefix+m.t.Separator()+"#"] = c.size + 1
+}
+
+// shift renumbers the elements of the collection at prefix with an index
+// equal or greater than from by adding delta to their index
+func (m *Map) shift(prefix string, from, delta int) {
+	p := prefix + m.t.Separator()
+	moved := map[string]interface{}{}
+	for k, v := range m.m {
+		i, rest, ok := m.elementKey(p, k)
+		if !ok || i < from {
+			continue
+		}
+		delete(m.m, k)
+		moved[p+strconv.Itoa(i+delta)+rest] = v
+	}
+	for k, v := range moved {
+		m.m[k] = v
+	}
+}
@@ -0,0 +1,198 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flatex
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMap_collection(t *testing.T) {
+	in := map[string]interface{}{
+		"a": []interface{}{
+			map[string]interface{}{
+				"b": []interface{}{1, 2},
+				"c": 0,
+			},
+			map[string]interface{}{
+				"b": []interface{}{3},
+				"c": 1,
+			},
+			map[string]interface{}{
+				"c": 2,
+			},
+		},
+		"d": 42,
+	}
+
+	for _, tc := range []struct {
+		name string
+		op   func(*Map) error
+		err  error
+		out  map[string]interface{}
+	}{
+		{
+			name: "remove",
+			op:   func(m *Map) error { return m.RemoveAt("a", 0) },
+			out: map[string]interface{}{
+				"a.#":     2,
+				"a.0.b.#": 1,
+				"a.0.b.0": 3,
+				"a.0.c":   1,
+				"a.1.c":   2,
+				"d":       42,
+			},
+		},
+		{
+			name: "remove_negative",
+			op:   func(m *Map) error { return m.RemoveAt("a", -2) },
+			out: map[string]interface{}{
+				"a.#":     2,
+				"a.0.b.#": 2,
+				"a.0.b.0": 1,
+				"a.0.b.1": 2,
+				"a.0.c":   0,
+				"a.1.c":   2,
+				"d":       42,
+			},
+		},
+		{
+			name: "remove_nested",
+			op:   func(m *Map) error { return m.RemoveAt("a.0.b", 0) },
+			out: map[string]interface{}{
+				"a.#":     3,
+				"a.0.b.#": 1,
+				"a.0.b.0": 2,
+				"a.0.c":   0,
+				"a.1.b.#": 1,
+				"a.1.b.0": 3,
+				"a.1.c":   1,
+				"a.2.c":   2,
+				"d":       42,
+			},
+		},
+		{
+			name: "remove_out_of_range",
+			op:   func(m *Map) error { return m.RemoveAt("a", 3) },
+			err:  ErrIndexOutOfRange,
+		},
+		{
+			name: "remove_with_missing_collection",
+			op:   func(m *Map) error { return m.RemoveAt("a.*.b", 0) },
+			out: map[string]interface{}{
+				"a.#":     3,
+				"a.0.b.#": 1,
+				"a.0.b.0": 2,
+				"a.0.c":   0,
+				"a.1.b.#": 0,
+				"a.1.c":   1,
+				"a.2.c":   2,
+				"d":       42,
+			},
+		},
+		{
+			name: "remove_without_collections",
+			op:   func(m *Map) error { return m.RemoveAt("a.*.c", 0) },
+			err:  ErrNotACollection,
+		},
+		{
+			name: "remove_not_a_collection",
+			op:   func(m *Map) error { return m.RemoveAt("d", 0) },
+			err:  ErrNotACollection,
+		},
+		{
+			name: "insert",
+			op: func(m *Map) error {
+				return m.InsertAt("a", 1, map[string]interface{}{"b": []interface{}{"x"}})
+			},
+			out: map[string]interface{}{
+				"a.#":     4,
+				"a.0.b.#": 2,
+				"a.0.b.0": 1,
+				"a.0.b.1": 2,
+				"a.0.c":   0,
+				"a.1.b.#": 1,
+				"a.1.b.0": "x",
+				"a.2.b.#": 1,
+				"a.2.b.0": 3,
+				"a.2.c":   1,
+				"a.3.c":   2,
+				"d":       42,
+			},
+		},
+		{
+			name: "insert_out_of_range",
+			op:   func(m *Map) error { return m.InsertAt("a", 4, 1) },
+			err:  ErrIndexOutOfRange,
+		},
+		{
+			name: "insert_with_wildcard",
+			op:   func(m *Map) error { return m.InsertAt("a.:2.b", 0, 0) },
+			out: map[string]interface{}{
+				"a.#":     3,
+				"a.0.b.#": 3,
+				"a.0.b.0": 0,
+				"a.0.b.1": 1,
+				"a.0.b.2": 2,
+				"a.0.c":   0,
+				"a.1.b.#": 2,
+				"a.1.b.0": 0,
+				"a.1.b.1": 3,
+				"a.1.c":   1,
+				"a.2.c":   2,
+				"d":       42,
+			},
+		},
+		{
+			name: "append",
+			op:   func(m *Map) error { return m.AppendTo("a.-3.b", []interface{}{true}) },
+			out: map[string]interface{}{
+				"a.#":       3,
+				"a.0.b.#":   3,
+				"a.0.b.0":   1,
+				"a.0.b.1":   2,
+				"a.0.b.2.#": 1,
+				"a.0.b.2.0": true,
+				"a.0.c":     0,
+				"a.1.b.#":   1,
+				"a.1.b.0":   3,
+				"a.1.c":     1,
+				"a.2.c":     2,
+				"d":         42,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			res, _ := Flatten(in, DefaultTokenizer)
+			original := res.Expand()
+
+			err := tc.op(res)
+			if err != tc.err {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if err != nil {
+				if !reflect.DeepEqual(res.Expand(), original) {
+					t.Errorf("the map has been modified: %+v", res.m)
+				}
+				return
+			}
+			if !reflect.DeepEqual(res.m, tc.out) {
+				t.Errorf("unexpected result:\n%+v\n%+v", res.m, tc.out)
+			}
+		})
+	}
+}
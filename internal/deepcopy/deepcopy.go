@@ -0,0 +1,69 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package deepcopy copies the values stored in maps and trees
+package deepcopy
+
+import "reflect"
+
+// Copy copies the maps and slices in v, recursively
+func Copy(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for k, x := range v {
+			res[k] = Copy(x)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, x := range v {
+			res[i] = Copy(x)
+		}
+		return res
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.IsNil() {
+			return v
+		}
+		res := reflect.MakeMapWithSize(rv.Type(), rv.Len())
+		for it := rv.MapRange(); it.Next(); {
+			res.SetMapIndex(it.Key(), copyValue(it.Value()))
+		}
+		return res.Interface()
+	case reflect.Slice:
+		if rv.IsNil() {
+			return v
+		}
+		res := reflect.MakeSlice(rv.Type(), rv.Len(), rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			res.Index(i).Set(copyValue(rv.Index(i)))
+		}
+		return res.Interface()
+	}
+	return v
+}
+
+func copyValue(v reflect.Value) reflect.Value {
+	res := reflect.ValueOf(Copy(v.Interface()))
+	if !res.IsValid() {
+		return reflect.Zero(v.Type())
+	}
+	return res
+}
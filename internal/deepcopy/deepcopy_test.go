@@ -0,0 +1,50 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deepcopy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCopy(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   interface{}
+	}{
+		{name: "scalar", in: 42},
+		{name: "nil", in: nil},
+		{name: "map", in: map[string]interface{}{"a": []interface{}{1, map[string]interface{}{"b": 2}}}},
+		{name: "typed_slice", in: [][]string{{"a"}, nil, {"b", "c"}}},
+		{name: "typed_map", in: map[string][]int{"a": {1, 2}}},
+		{name: "interface_slice", in: []interface{}{nil, []string{"a"}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			res := Copy(tc.in)
+			if !reflect.DeepEqual(res, tc.in) {
+				t.Errorf("unexpected copy: %v", res)
+			}
+		})
+	}
+
+	in := [][]string{{"a"}}
+	res := Copy(in).([][]string)
+	in[0][0] = "changed"
+	if res[0][0] != "a" {
+		t.Error("the nested slice has not been copied")
+	}
+}
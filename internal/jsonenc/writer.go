@@ -0,0 +1,299 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package jsonenc writes JSON documents token by token, producing the same
+// output as encoding/json without building the values to encode
+package jsonenc
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const bufferSize = 4096
+
+// Writer writes the tokens of a JSON document to an io.Writer. The first
+// error is kept and returned by Flush, which must be called once the
+// document is complete.
+type Writer struct {
+	w      io.Writer
+	buf    []byte
+	indent string
+	depth  int
+	// first is true until the first element of the current object or
+	// collection is written
+	first    bool
+	afterKey bool
+	err      error
+}
+
+// NewWriter returns a Writer indenting the document with the given string,
+// or writing it compactly if the indentation is empty
+func NewWriter(w io.Writer, indent string) *Writer {
+	return &Writer{
+		w:      w,
+		buf:    make([]byte, 0, bufferSize),
+		indent: indent,
+	}
+}
+
+func (e *Writer) BeginObject() {
+	e.element()
+	e.open('{')
+}
+
+func (e *Writer) EndObject() {
+	e.close('}')
+}
+
+func (e *Writer) BeginArray() {
+	e.element()
+	e.open('[')
+}
+
+func (e *Writer) EndArray() {
+	e.close(']')
+}
+
+// Key writes the key of the next member of the current object
+func (e *Writer) Key(k string) {
+	e.element()
+	e.buf = AppendString(e.buf, k)
+	e.buf = append(e.buf, ':')
+	if e.indent != "" {
+		e.buf = append(e.buf, ' ')
+	}
+	e.afterKey = true
+}
+
+// Value writes a value that is encoded as encoding/json.Marshal does
+func (e *Writer) Value(v interface{}) {
+	e.element()
+	if e.err != nil {
+		return
+	}
+	start := len(e.buf)
+	e.buf, e.err = AppendValue(e.buf, v)
+	if e.err == nil && e.indent != "" && len(e.buf) > start && (e.buf[start] == '{' || e.buf[start] == '[') {
+		e.reindent(start)
+	}
+	e.flushFull()
+}
+
+// reindent indents the composite value written from the given position,
+// which encoding/json encodes compactly
+func (e *Writer) reindent(start int) {
+	res := &bytes.Buffer{}
+	e.err = json.Indent(res, e.buf[start:], strings.Repeat(e.indent, e.depth), e.indent)
+	e.buf = append(e.buf[:start], res.Bytes()...)
+}
+
+// Flush writes the buffered output and returns the first error found
+func (e *Writer) Flush() error {
+	if e.err != nil {
+		return e.err
+	}
+	if len(e.buf) > 0 {
+		_, e.err = e.w.Write(e.buf)
+		e.buf = e.buf[:0]
+	}
+	return e.err
+}
+
+func (e *Writer) open(c byte) {
+	e.buf = append(e.buf, c)
+	e.depth++
+	e.first = true
+}
+
+func (e *Writer) close(c byte) {
+	e.depth--
+	if !e.first {
+		e.newline()
+	}
+	e.buf = append(e.buf, c)
+	e.first = false
+	e.flushFull()
+}
+
+// element writes the separator needed before a new element
+func (e *Writer) element() {
+	if e.afterKey {
+		e.afterKey = false
+		return
+	}
+	if e.depth == 0 {
+		return
+	}
+	if !e.first {
+		e.buf = append(e.buf, ',')
+	}
+	e.first = false
+	e.newline()
+}
+
+func (e *Writer) newline() {
+	if e.indent == "" {
+		return
+	}
+	e.buf = append(e.buf, '\n')
+	for i := 0; i < e.depth; i++ {
+		e.buf = append(e.buf, e.indent...)
+	}
+}
+
+func (e *Writer) flushFull() {
+	if len(e.buf) >= bufferSize {
+		e.Flush()
+	}
+}
+
+// AppendValue appends the encoding of v as encoding/json.Marshal returns it
+func AppendValue(b []byte, v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return append(b, "null"...), nil
+	case bool:
+		return strconv.AppendBool(b, v), nil
+	case string:
+		return AppendString(b, v), nil
+	case []interface{}:
+		if len(v) == 0 {
+			return append(b, "[]"...), nil
+		}
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return append(b, "{}"...), nil
+		}
+	case float64:
+		if !math.IsInf(v, 0) && !math.IsNaN(v) {
+			return appendFloat(b, v, 64), nil
+		}
+	case float32:
+		if f := float64(v); !math.IsInf(f, 0) && !math.IsNaN(f) {
+			return appendFloat(b, f, 32), nil
+		}
+	case int:
+		return strconv.AppendInt(b, int64(v), 10), nil
+	case int8:
+		return strconv.AppendInt(b, int64(v), 10), nil
+	case int16:
+		return strconv.AppendInt(b, int64(v), 10), nil
+	case int32:
+		return strconv.AppendInt(b, int64(v), 10), nil
+	case int64:
+		return strconv.AppendInt(b, v, 10), nil
+	case uint:
+		return strconv.AppendUint(b, uint64(v), 10), nil
+	case uint8:
+		return strconv.AppendUint(b, uint64(v), 10), nil
+	case uint16:
+		return strconv.AppendUint(b, uint64(v), 10), nil
+	case uint32:
+		return strconv.AppendUint(b, uint64(v), 10), nil
+	case uint64:
+		return strconv.AppendUint(b, v, 10), nil
+	}
+
+	res, err := json.Marshal(v)
+	if err != nil {
+		return b, err
+	}
+	return append(b, res...), nil
+}
+
+// appendFloat formats the number as encoding/json does, switching to the
+// exponent notation for very small and very large numbers
+func appendFloat(b []byte, f float64, bits int) []byte {
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	b = strconv.AppendFloat(b, f, format, -1, bits)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		n := len(b)
+		if n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	return b
+}
+
+const hex = "0123456789abcdef"
+
+// AppendString appends the quoted string escaping it as encoding/json does,
+// including the HTML characters
+func AppendString(b []byte, s string) []byte {
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\b':
+				b = append(b, '\\', 'b')
+			case '\f':
+				b = append(b, '\\', 'f')
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, s[start:i]...)
+			b = append(b, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		// U+2028 and U+2029 are valid JSON but not valid JavaScript
+		if r == '\u2028' || r == '\u2029' {
+			b = append(b, s[start:i]...)
+			b = append(b, '\\', 'u', '2', '0', '2', hex[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
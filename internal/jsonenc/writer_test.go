@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package jsonenc
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   interface{}
+	}{
+		{name: "null", in: nil},
+		{name: "strings", in: []interface{}{"", "a\"b\\c", "<a&b>", "\b\f\n\r\t\x00\x1f", "ñ€😀", "  ", "\xff\xfeok"}},
+		{name: "numbers", in: []interface{}{0, -1, 1.5, 1e21, 1e20, 1e-7, 0.000001, -2.5e-10, float32(3.14), float32(1e-7), int8(-8), uint64(math.MaxUint64), json.Number("12.50")}},
+		{name: "booleans", in: []interface{}{true, false}},
+		{name: "empty", in: map[string]interface{}{"a": []interface{}{}, "b": map[string]interface{}{}}},
+		{
+			name: "nested",
+			in: map[string]interface{}{
+				"b": []interface{}{map[string]interface{}{"x": 1, "y": []interface{}{nil, "z"}}, 2},
+				"a": map[string]interface{}{"c": true},
+			},
+		},
+		{name: "other_types", in: []interface{}{[]string{"a", "b"}, map[string]int{"a": 1}}},
+		{name: "long", in: []interface{}{strings.Repeat("x", 10000), strings.Repeat("y", 5000)}},
+	} {
+		for _, indent := range []string{"", "\t", "  "} {
+			t.Run(tc.name+"/"+indent, func(t *testing.T) {
+				var expected []byte
+				var err error
+				if indent == "" {
+					expected, err = json.Marshal(tc.in)
+				} else {
+					expected, err = json.MarshalIndent(tc.in, "", indent)
+				}
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				buf := &bytes.Buffer{}
+				w := NewWriter(buf, indent)
+				write(w, tc.in)
+				if err := w.Flush(); err != nil {
+					t.Error(err)
+					return
+				}
+				if buf.String() != string(expected) {
+					t.Errorf("unexpected result:\n%s\nexpected:\n%s", buf.String(), expected)
+				}
+			})
+		}
+	}
+}
+
+func TestWriter_error(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf, "")
+	w.BeginArray()
+	w.Value(1)
+	w.Value(math.NaN())
+	w.Value(2)
+	w.EndArray()
+	if _, ok := w.Flush().(*json.UnsupportedValueError); !ok {
+		t.Error("expecting an unsupported value error")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("unexpected output: %s", buf.String())
+	}
+}
+
+func write(w *Writer, v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		w.BeginObject()
+		for _, k := range keys {
+			w.Key(k)
+			write(w, v[k])
+		}
+		w.EndObject()
+	case []interface{}:
+		w.BeginArray()
+		for _, x := range v {
+			write(w, x)
+		}
+		w.EndArray()
+	default:
+		w.Value(v)
+	}
+}
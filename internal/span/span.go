@@ -0,0 +1,87 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package span parses the negative indices and the slice segments addressing
+// the elements of collections
+package span
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Range is the range of positions [Lo, Hi) selected by a negative index
+// ("-1") or a slice segment ("1:3", ":-1", "2:") in a collection. Single is
+// true for the negative indices.
+type Range struct {
+	Lo, Hi int
+	Single bool
+}
+
+// Parse resolves k against a collection of the given size. It returns false
+// if k is neither a negative index nor a slice segment, so plain labels keep
+// their usual meaning.
+func Parse(k string, size int) (Range, bool) {
+	if k == "" || (k[0] != '-' && strings.IndexByte(k, ':') == -1) {
+		return Range{}, false
+	}
+
+	i := strings.IndexByte(k, ':')
+	if i == -1 {
+		n, err := strconv.Atoi(k)
+		if err != nil {
+			return Range{}, false
+		}
+		n += size
+		if n < 0 || n >= size {
+			return Range{Single: true}, true
+		}
+		return Range{Lo: n, Hi: n + 1, Single: true}, true
+	}
+
+	lo, ok := parseBound(k[:i], 0, size)
+	if !ok {
+		return Range{}, false
+	}
+	hi, ok := parseBound(k[i+1:], size, size)
+	if !ok {
+		return Range{}, false
+	}
+	if hi < lo {
+		hi = lo
+	}
+	return Range{Lo: lo, Hi: hi}, true
+}
+
+func parseBound(s string, def, size int) (int, bool) {
+	if s == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, false
+	}
+	if n < 0 {
+		n += size
+	}
+	if n < 0 {
+		return 0, true
+	}
+	if n > size {
+		return size, true
+	}
+	return n, true
+}
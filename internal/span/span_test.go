@@ -0,0 +1,43 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package span
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		k   string
+		out Range
+		ok  bool
+	}{
+		{k: "1", ok: false},
+		{k: "a", ok: false},
+		{k: "-1", out: Range{Lo: 4, Hi: 5, Single: true}, ok: true},
+		{k: "-6", out: Range{Single: true}, ok: true},
+		{k: "1:3", out: Range{Lo: 1, Hi: 3}, ok: true},
+		{k: ":-1", out: Range{Lo: 0, Hi: 4}, ok: true},
+		{k: "2:", out: Range{Lo: 2, Hi: 5}, ok: true},
+		{k: "3:1", out: Range{Lo: 3, Hi: 3}, ok: true},
+		{k: "-10:10", out: Range{Lo: 0, Hi: 5}, ok: true},
+		{k: "a:1", ok: false},
+	} {
+		out, ok := Parse(tc.k, 5)
+		if ok != tc.ok || out != tc.out {
+			t.Errorf("%s: unexpected result: %v %v", tc.k, out, ok)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flatex
+
+import (
+	"cmp"
+	"iter"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// All returns an iterator over the keys and the values of the map, in no
+// particular order. The map must not be modified while iterating.
+func (m *Map) All() iter.Seq2[string, interface{}] {
+	return func(yield func(string, interface{}) bool) {
+		for k, v := range m.m {
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// Sorted returns an iterator over the keys and the values of the map, sorted
+// segment by segment: the counters of the collections come first, followed
+// by the numeric segments ordered by value and the rest of segments ordered
+// lexically. The map must not be modified while iterating.
+func (m *Map) Sorted() iter.Seq2[string, interface{}] {
+	return func(yield func(string, interface{}) bool) {
+		for _, k := range m.sortedKeys().keys {
+			if !yield(k, m.m[k]) {
+				return
+			}
+		}
+	}
+}
+
+// notIndex marks the segments that are not numbers
+const notIndex = math.MinInt
+
+// sortedKeys returns the keys of the map sorted as Sorted iterates them,
+// along with their segments. The segments are parsed once, so the sort does
+// not parse them on every comparison.
+func (m *Map) sortedKeys() sortableKeys {
+	ks := sortableKeys{
+		keys:     make([]string, 0, len(m.m)),
+		segments: make([][]string, 0, len(m.m)),
+		indexes:  make([][]int, 0, len(m.m)),
+	}
+	total := 0
+	for k := range m.m {
+		segments := m.t.Keys(k)
+		ks.keys = append(ks.keys, k)
+		ks.segments = append(ks.segments, segments)
+		total += len(segments)
+	}
+
+	indexes := make([]int, total)
+	for _, segments := range ks.segments {
+		res := indexes[:len(segments):len(segments)]
+		indexes = indexes[len(segments):]
+		for i, k := range segments {
+			res[i] = notIndex
+			if x, ok := atoi(k); ok {
+				res[i] = x
+			}
+		}
+		ks.indexes = append(ks.indexes, res)
+	}
+
+	order := make([]int, len(ks.keys))
+	for i := range order {
+		order[i] = i
+	}
+	// sorting the positions of the keys is cheaper than swapping them
+	slices.SortFunc(order, ks.compare)
+
+	res := sortableKeys{
+		keys:     make([]string, len(ks.keys)),
+		segments: make([][]string, len(ks.keys)),
+		indexes:  make([][]int, len(ks.keys)),
+	}
+	for i, j := range order {
+		res.keys[i] = ks.keys[j]
+		res.segments[i] = ks.segments[j]
+		res.indexes[i] = ks.indexes[j]
+	}
+	return res
+}
+
+// sortableKeys holds the keys of a map with their segments and the numbers
+// of the segments, or notIndex if they are not numbers
+type sortableKeys struct {
+	keys     []string
+	segments [][]string
+	indexes  [][]int
+}
+
+// compare compares the keys in the positions i and j segment by segment
+func (s sortableKeys) compare(i, j int) int {
+	a, b := s.segments[i], s.segments[j]
+	for k := 0; k < len(a) && k < len(b); k++ {
+		if a[k] == b[k] {
+			continue
+		}
+		x, y := s.indexes[i][k], s.indexes[j][k]
+		if r := cmp.Compare(segmentRank(a[k], x), segmentRank(b[k], y)); r != 0 {
+			return r
+		}
+		if x != notIndex && x != y {
+			return cmp.Compare(x, y)
+		}
+		// text segments, or numbers with the same value written differently
+		return strings.Compare(a[k], b[k])
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+// segmentRank orders the counters before the numbers and the numbers before
+// the rest of segments, so the order is the same whatever the segments
+// compared
+func segmentRank(k string, index int) int {
+	switch {
+	case k == "#":
+		return 0
+	case index != notIndex:
+		return 1
+	}
+	return 2
+}
+
+// atoi behaves like strconv.Atoi, but it does not build an error for the
+// segments that are not numbers
+func atoi(s string) (int, bool) {
+	digits := s
+	if len(digits) > 0 && (digits[0] == '-' || digits[0] == '+') {
+		digits = digits[1:]
+	}
+	if len(digits) == 0 {
+		return 0, false
+	}
+	for i := 0; i < len(digits); i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			return 0, false
+		}
+	}
+	i, err := strconv.Atoi(s)
+	return i, err == nil
+}
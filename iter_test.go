@@ -0,0 +1,91 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flatex
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMap_All(t *testing.T) {
+	res, _ := Flatten(map[string]interface{}{
+		"a": []interface{}{1, 2},
+		"b": map[string]interface{}{"c": true},
+	}, DefaultTokenizer)
+
+	all := map[string]interface{}{}
+	for k, v := range res.All() {
+		all[k] = v
+	}
+	if !reflect.DeepEqual(all, res.m) {
+		t.Errorf("unexpected result: %v", all)
+	}
+
+	n := 0
+	for range res.All() {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Errorf("unexpected number of iterations: %d", n)
+	}
+}
+
+func TestMap_Sorted(t *testing.T) {
+	collection := make([]interface{}, 12)
+	for i := range collection {
+		collection[i] = i
+	}
+	res, _ := Flatten(map[string]interface{}{
+		"a": collection,
+		"b": map[string]interface{}{"c": true, "a": false},
+		"#": 0,
+	}, DefaultTokenizer)
+
+	var keys []string
+	for k := range res.Sorted() {
+		keys = append(keys, k)
+	}
+
+	expected := []string{
+		"#", "a.#", "a.0", "a.1", "a.2", "a.3", "a.4", "a.5", "a.6", "a.7", "a.8", "a.9", "a.10", "a.11", "b.a", "b.c",
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("unexpected keys: %v", keys)
+	}
+}
+
+func TestMap_Sorted_mixedKeys(t *testing.T) {
+	in := map[string]interface{}{}
+	for _, k := range []string{"10", "2a", "3", "1b", "20", "x", "9", "#"} {
+		in[k] = map[string]interface{}{"p": 1, "q": 2}
+	}
+	res, _ := Flatten(in, DefaultTokenizer)
+
+	var keys []string
+	for k := range res.Sorted() {
+		keys = append(keys, k)
+	}
+
+	expected := []string{
+		"#.p", "#.q", "3.p", "3.q", "9.p", "9.q", "10.p", "10.q", "20.p", "20.q",
+		"1b.p", "1b.q", "2a.p", "2a.q", "x.p", "x.q",
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("unexpected keys: %v", keys)
+	}
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flatex
+
+import (
+	"io"
+	"sort"
+
+	"github.com/starvn/flatex/internal/jsonenc"
+)
+
+// JSONOptions customizes the output of WriteJSON
+type JSONOptions struct {
+	// SortKeys writes the keys of the objects sorted byte by byte, as
+	// encoding/json does. Otherwise they are written in the order Sorted
+	// iterates them, which puts the keys that are numbers first, ordered by
+	// value, so "9" goes before "10".
+	SortKeys bool
+	// Indent is repeated once per level of indentation. The output is
+	// compact if it is empty.
+	Indent string
+}
+
+// WriteJSON encodes the document Expand would return straight from the keys
+// of the map
+func (m *Map) WriteJSON(w io.Writer, opts JSONOptions) error {
+	e := mapEncoder{
+		enc:          jsonenc.NewWriter(w, opts.Indent),
+		m:            m.m,
+		sortableKeys: m.sortedKeys(),
+		sortKeys:     opts.SortKeys,
+	}
+	e.object(0, len(e.keys), 0)
+	return e.enc.Flush()
+}
+
+func (s *MapSnapshot) WriteJSON(w io.Writer, opts JSONOptions) error {
+	return s.m.WriteJSON(w, opts)
+}
+
+// mapEncoder writes the sorted keys of a map as a tree. Every range of keys
+// it writes shares the segments before the given depth.
+type mapEncoder struct {
+	sortableKeys
+	enc      *jsonenc.Writer
+	m        map[string]interface{}
+	sortKeys bool
+}
+
+func (e mapEncoder) value(lo, hi, d int) {
+	if len(e.segments[lo]) == d {
+		e.enc.Value(e.m[e.keys[lo]])
+		return
+	}
+	if e.segments[lo][d] == "#" {
+		e.collection(lo, hi, d)
+		return
+	}
+	e.object(lo, hi, d)
+}
+
+func (e mapEncoder) object(lo, hi, d int) {
+	e.enc.BeginObject()
+	if e.sortKeys && !e.bytewise(lo, hi, d) {
+		var members [][2]int
+		for lo < hi {
+			end := e.group(lo, hi, d)
+			members = append(members, [2]int{lo, end})
+			lo = end
+		}
+		sort.Slice(members, func(i, j int) bool {
+			return e.segments[members[i][0]][d] < e.segments[members[j][0]][d]
+		})
+		for _, m := range members {
+			e.member(m[0], m[1], d)
+		}
+	} else {
+		for lo < hi {
+			end := e.group(lo, hi, d)
+			e.member(lo, end, d)
+			lo = end
+		}
+	}
+	e.enc.EndObject()
+}
+
+func (e mapEncoder) member(lo, hi, d int) {
+	e.enc.Key(e.segments[lo][d])
+	e.value(lo, hi, d+1)
+}
+
+// bytewise reports if the members of the object are already sorted byte by
+// byte, which is the case unless some of their keys are numbers
+func (e mapEncoder) bytewise(lo, hi, d int) bool {
+	for lo < hi {
+		end := e.group(lo, hi, d)
+		if end < hi && e.segments[end][d] < e.segments[lo][d] {
+			return false
+		}
+		lo = end
+	}
+	return true
+}
+
+// collection writes as many elements as the counter says, which comes first
+// in the range. The missing elements are written as null and the ones out of
+// range are skipped.
+func (e mapEncoder) collection(lo, hi, d int) {
+	size, _ := e.m[e.keys[lo]].(int)
+	lo = e.group(lo, hi, d)
+
+	e.enc.BeginArray()
+	for i := 0; i < size; i++ {
+		for lo < hi && (e.indexes[lo][d] == notIndex || e.indexes[lo][d] < i) {
+			lo = e.group(lo, hi, d)
+		}
+		if lo < hi && e.indexes[lo][d] == i {
+			end := e.group(lo, hi, d)
+			e.value(lo, end, d+1)
+			lo = end
+			continue
+		}
+		e.enc.Value(nil)
+	}
+	e.enc.EndArray()
+}
+
+// group returns the end of the keys sharing the segment d with the key lo
+func (e mapEncoder) group(lo, hi, d int) int {
+	k := e.segments[lo][d]
+	end := lo + 1
+	for end < hi && len(e.segments[end]) > d && e.segments[end][d] == k {
+		end++
+	}
+	return end
+}
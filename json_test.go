@@ -0,0 +1,159 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flatex
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMap_WriteJSON(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   map[string]interface{}
+	}{
+		{
+			name: "empty",
+			in:   map[string]interface{}{},
+		},
+		{
+			name: "nested",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"b": []interface{}{1, "x", nil}, "c": map[string]interface{}{"d": true}},
+					map[string]interface{}{"b": []interface{}{}},
+					"<s>",
+				},
+				"e": map[string]interface{}{"f": 1.5, "g": []string{"typed"}},
+				"h": false,
+			},
+		},
+		{
+			name: "long_collection",
+			in:   map[string]interface{}{"a": []interface{}{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}},
+		},
+		{
+			name: "numeric_keys",
+			in: map[string]interface{}{
+				"a": map[string]interface{}{"9": 1, "10": []interface{}{2, 3}, "b": 4, "-1": 5},
+			},
+		},
+		{
+			name: "mixed_keys",
+			in: map[string]interface{}{
+				"3c":  map[string]interface{}{"p": 1, "q": 2},
+				"a1":  map[string]interface{}{"p": 1, "q": 2},
+				"9":   map[string]interface{}{"p": 1, "q": 2},
+				"90":  map[string]interface{}{"p": 1, "q": 2},
+				"100": map[string]interface{}{"p": 1, "q": 2},
+				"11z": map[string]interface{}{"p": 1, "q": 2},
+				"10":  map[string]interface{}{"p": 1, "q": 2},
+				"2a":  map[string]interface{}{"p": 1, "q": 2},
+				"x":   map[string]interface{}{"p": 1, "q": 2},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m, _ := Flatten(tc.in, DefaultTokenizer)
+
+			for _, indent := range []string{"", "\t"} {
+				buf := &bytes.Buffer{}
+				if err := m.WriteJSON(buf, JSONOptions{SortKeys: true, Indent: indent}); err != nil {
+					t.Error(err)
+					continue
+				}
+				expected, _ := json.MarshalIndent(m.Expand(), "", indent)
+				if indent == "" {
+					expected, _ = json.Marshal(m.Expand())
+				}
+				if buf.String() != string(expected) {
+					t.Errorf("unexpected result:\n%s\nexpected:\n%s", buf.String(), expected)
+				}
+			}
+
+			// the default order may differ from encoding/json, but not the
+			// decoded document. The keys are sorted from the map iteration
+			// order, so they are written a few times.
+			b, _ := json.Marshal(m.Expand())
+			var expected interface{}
+			json.Unmarshal(b, &expected)
+			for i := 0; i < 10; i++ {
+				buf := &bytes.Buffer{}
+				if err := m.WriteJSON(buf, JSONOptions{}); err != nil {
+					t.Error(err)
+					return
+				}
+				var res interface{}
+				if err := json.Unmarshal(buf.Bytes(), &res); err != nil {
+					t.Errorf("invalid output %s: %v", buf.String(), err)
+					return
+				}
+				if !reflect.DeepEqual(res, expected) {
+					t.Errorf("unexpected document: %v", res)
+					return
+				}
+				if strings.Count(buf.String(), `"p"`) != strings.Count(string(b), `"p"`) {
+					t.Errorf("unexpected duplicated keys: %s", buf.String())
+					return
+				}
+			}
+		})
+	}
+}
+
+func TestMap_WriteJSON_order(t *testing.T) {
+	m, _ := Flatten(map[string]interface{}{
+		"a": map[string]interface{}{"9": 1, "10": 2, "b": 3},
+	}, DefaultTokenizer)
+
+	for _, tc := range []struct {
+		opts     JSONOptions
+		expected string
+	}{
+		{opts: JSONOptions{}, expected: `{"a":{"9":1,"10":2,"b":3}}`},
+		{opts: JSONOptions{SortKeys: true}, expected: `{"a":{"10":2,"9":1,"b":3}}`},
+	} {
+		buf := &bytes.Buffer{}
+		if err := m.WriteJSON(buf, tc.opts); err != nil {
+			t.Error(err)
+		}
+		if buf.String() != tc.expected {
+			t.Errorf("unexpected result: %s", buf.String())
+		}
+	}
+}
+
+func TestMap_WriteJSON_sparse(t *testing.T) {
+	m, _ := newMap(DefaultTokenizer)
+	m.m = map[string]interface{}{
+		"a.#":   3,
+		"a.1.b": 1,
+		"a.5":   2,
+		"c":     []interface{}{},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := NewSyncMap(m).Snapshot().WriteJSON(buf, JSONOptions{}); err != nil {
+		t.Error(err)
+	}
+	if expected := `{"a":[null,{"b":1},null],"c":[]}`; buf.String() != expected {
+		t.Errorf("unexpected result: %s", buf.String())
+	}
+}
@@ -49,7 +49,20 @@ type Map struct {
 	re *regexp.Regexp
 }
 
+// Move renames the keys with the given prefix. Negative indices and slice
+// segments in the original prefix select elements of collections, which are
+// renumbered once their elements are moved out.
 func (m *Map) Move(original, newKey string) {
+	if strings.ContainsAny(original, "-:") {
+		if src := m.t.Keys(original); lastSpan(src) > -1 {
+			m.moveSpans(src, m.t.Keys(newKey))
+			return
+		}
+	}
+	m.move(original, newKey)
+}
+
+func (m *Map) move(original, newKey string) {
 	if v, ok := m.m[original]; ok {
 		m.m[newKey] = v
 		delete(m.m, original)
@@ -77,8 +90,20 @@ func (m *Map) Move(original, newKey string) {
 	}
 }
 
-// Del deletes a key out of the map with the given prefix
+// Del deletes a key out of the map with the given prefix. Negative indices
+// and slice segments select elements of collections, and removing whole
+// elements keeps the remaining indices and the counter consistent.
 func (m *Map) Del(prefix string) {
+	if strings.ContainsAny(prefix, "-:") {
+		if ks := m.t.Keys(prefix); lastSpan(ks) > -1 {
+			m.delSpans(ks)
+			return
+		}
+	}
+	m.del(prefix)
+}
+
+func (m *Map) del(prefix string) {
 	if _, ok := m.m[prefix]; ok {
 		delete(m.m, prefix)
 		return
@@ -121,7 +146,7 @@ func (m *Map) delSliceAttribute(prefix string) {
 
 		if recursive {
 			newPref := k[:i+1+strings.Index(k[i+1:], sep)] + prefixRemainder
-			m.Del(newPref)
+			m.del(newPref)
 			continue
 		}
 
@@ -164,7 +189,7 @@ func (m *Map) moveSliceAttribute(original, newKey string) {
 		keyPrefix := newKeyPrefix + k[i:i+idLen]
 
 		if recursive {
-			m.Move(k[:i+idLen]+originalRemainder, keyPrefix+newKeyRemainder)
+			m.move(k[:i+idLen]+originalRemainder, keyPrefix+newKeyRemainder)
 			continue
 		}
 
@@ -185,6 +210,43 @@ func (m *Map) moveSliceAttribute(original, newKey string) {
 	}
 }
 
+// Append moves the elements of the src collection to the end of the dst
+// collection and deletes src. The wildcards in dst are replaced by the
+// segments matched by the wildcards in src.
+func (m *Map) Append(src, dst string) {
+	srcKs, dstKs := m.t.Keys(src), m.t.Keys(dst)
+	sep := m.t.Separator()
+
+	for _, s := range m.expandSpans(srcKs, len(srcKs)) {
+		srcSize, ok := m.size(s.keys)
+		if !ok {
+			continue
+		}
+		target := m.spanTarget(srcKs, dstKs, len(srcKs), s.keys)
+		dstSize, ok := m.size(target)
+		if !ok {
+			continue
+		}
+
+		from, to := m.t.Token(s.keys)+sep, m.t.Token(target)+sep
+		moved := map[string]interface{}{}
+		for k, v := range m.m {
+			i, rest, ok := m.elementKey(from, k)
+			if !ok {
+				continue
+			}
+			delete(m.m, k)
+			moved[to+strconv.Itoa(dstSize+i)+rest] = v
+		}
+		for k, v := range moved {
+			m.m[k] = v
+		}
+
+		delete(m.m, from+"#")
+		m.m[to+"#"] = dstSize + srcSize
+	}
+}
+
 func (m *Map) Expand() map[string]interface{} {
 	res := map[string]interface{}{}
 	hasCollections := false
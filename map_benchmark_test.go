@@ -17,7 +17,9 @@
 package flatex
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"testing"
 )
 
@@ -108,6 +110,36 @@ func BenchmarkMove(b *testing.B) {
 	result = res
 }
 
+func BenchmarkWriteJSON(b *testing.B) {
+	for _, size := range []int{1, 5, 50, 500} {
+		b.Run(fmt.Sprintf("%d", size), func(b *testing.B) {
+
+			flatten, _ := Flatten(getInputData(size), DefaultTokenizer)
+
+			b.ResetTimer()
+
+			for n := 0; n < b.N; n++ {
+				flatten.WriteJSON(io.Discard, JSONOptions{})
+			}
+		})
+	}
+}
+
+func BenchmarkMarshalExpanded(b *testing.B) {
+	for _, size := range []int{1, 5, 50, 500} {
+		b.Run(fmt.Sprintf("%d", size), func(b *testing.B) {
+
+			flatten, _ := Flatten(getInputData(size), DefaultTokenizer)
+
+			b.ResetTimer()
+
+			for n := 0; n < b.N; n++ {
+				json.NewEncoder(io.Discard).Encode(flatten.Expand())
+			}
+		})
+	}
+}
+
 func getInputData(size int) map[string]interface{} {
 	first := map[string]interface{}{
 		"b": []interface{}{
@@ -291,6 +291,91 @@ func TestMap_Del(t *testing.T) {
 				"turbo":   false,
 			},
 		},
+		{
+			name:    "negative_index",
+			pattern: "a.-1",
+			in: map[string]interface{}{
+				"a": []interface{}{1, 2, 3},
+			},
+			out: map[string]interface{}{
+				"a.#": 2,
+				"a.0": 1,
+				"a.1": 2,
+			},
+		},
+		{
+			name:    "negative_index_in_the_middle",
+			pattern: "a.-2",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"b": 1},
+					map[string]interface{}{"b": 2},
+					map[string]interface{}{"b": []interface{}{3, 4}},
+				},
+			},
+			out: map[string]interface{}{
+				"a.#":     2,
+				"a.0.b":   1,
+				"a.1.b.#": 2,
+				"a.1.b.0": 3,
+				"a.1.b.1": 4,
+			},
+		},
+		{
+			name:    "negative_index_attribute",
+			pattern: "a.-1.b",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"b": 1, "c": 1},
+					map[string]interface{}{"b": 2, "c": 2},
+				},
+			},
+			out: map[string]interface{}{
+				"a.#":   2,
+				"a.0.b": 1,
+				"a.0.c": 1,
+				"a.1.c": 2,
+			},
+		},
+		{
+			name:    "negative_index_in_struct",
+			pattern: "a.-1",
+			in: map[string]interface{}{
+				"a": map[string]interface{}{"-1": 1, "b": 2},
+			},
+			out: map[string]interface{}{
+				"a.b": 2,
+			},
+		},
+		{
+			name:    "slice_range",
+			pattern: "a.1:3",
+			in: map[string]interface{}{
+				"a": []interface{}{1, 2, 3, 4, 5},
+			},
+			out: map[string]interface{}{
+				"a.#": 3,
+				"a.0": 1,
+				"a.1": 4,
+				"a.2": 5,
+			},
+		},
+		{
+			name:    "slice_range_in_nested_collections",
+			pattern: "a.*.b.:1",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"b": []interface{}{1, 2}},
+					map[string]interface{}{"b": []interface{}{3}},
+				},
+			},
+			out: map[string]interface{}{
+				"a.#":     2,
+				"a.0.b.#": 1,
+				"a.0.b.0": 2,
+				"a.1.b.#": 0,
+			},
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			res, _ := Flatten(tc.in, DefaultTokenizer)
@@ -482,6 +567,72 @@ func TestMap_Move(t *testing.T) {
 				"turbo":       false,
 			},
 		},
+		{
+			name: "negative_index",
+			src:  "a.-1",
+			dst:  "b",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"c": 1},
+					map[string]interface{}{"c": 2},
+				},
+			},
+			out: map[string]interface{}{
+				"a.#":   1,
+				"a.0.c": 1,
+				"b.c":   2,
+			},
+		},
+		{
+			name: "negative_index_attribute",
+			src:  "a.-1.c",
+			dst:  "a.-1.x",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"c": 1},
+					map[string]interface{}{"c": 2},
+				},
+			},
+			out: map[string]interface{}{
+				"a.#":   2,
+				"a.0.c": 1,
+				"a.1.x": 2,
+			},
+		},
+		{
+			name: "negative_index_with_wildcard",
+			src:  "a.*.b.-1",
+			dst:  "a.*.last",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"b": []interface{}{1, 2}},
+					map[string]interface{}{"b": []interface{}{3}},
+				},
+			},
+			out: map[string]interface{}{
+				"a.#":      2,
+				"a.0.b.#":  1,
+				"a.0.b.0":  1,
+				"a.0.last": 2,
+				"a.1.b.#":  0,
+				"a.1.last": 3,
+			},
+		},
+		{
+			name: "slice_range",
+			src:  "a.:2",
+			dst:  "b",
+			in: map[string]interface{}{
+				"a": []interface{}{1, 2, 3},
+			},
+			out: map[string]interface{}{
+				"a.#": 1,
+				"a.0": 3,
+				"b.#": 2,
+				"b.0": 1,
+				"b.1": 2,
+			},
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			res, _ := Flatten(tc.in, DefaultTokenizer)
@@ -494,6 +645,86 @@ func TestMap_Move(t *testing.T) {
 	}
 }
 
+func TestMap_Append(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		src  string
+		dst  string
+		in   map[string]interface{}
+		out  map[string]interface{}
+	}{
+		{
+			name: "plain",
+			src:  "a",
+			dst:  "b",
+			in:   map[string]interface{}{"a": []interface{}{42}, "b": []interface{}{1}},
+			out:  map[string]interface{}{"b.#": 2, "b.0": 1, "b.1": 42},
+		},
+		{
+			name: "nested",
+			src:  "a",
+			dst:  "b.c",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"x": []interface{}{1, 2}},
+				},
+				"b": map[string]interface{}{
+					"c": []interface{}{
+						map[string]interface{}{"x": []interface{}{3}},
+					},
+				},
+			},
+			out: map[string]interface{}{
+				"b.c.#":     2,
+				"b.c.0.x.#": 1,
+				"b.c.0.x.0": 3,
+				"b.c.1.x.#": 2,
+				"b.c.1.x.0": 1,
+				"b.c.1.x.1": 2,
+			},
+		},
+		{
+			name: "with_wildcard",
+			src:  "a.*.b",
+			dst:  "a.*.c",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"b": []interface{}{1, 2}, "c": []interface{}{0}},
+					map[string]interface{}{"b": []interface{}{3}, "c": []interface{}{}},
+					map[string]interface{}{"b": []interface{}{4}},
+				},
+			},
+			out: map[string]interface{}{
+				"a.#":     3,
+				"a.0.c.#": 3,
+				"a.0.c.0": 0,
+				"a.0.c.1": 1,
+				"a.0.c.2": 2,
+				"a.1.c.#": 1,
+				"a.1.c.0": 3,
+				"a.2.b.#": 1,
+				"a.2.b.0": 4,
+			},
+		},
+		{
+			name: "not_a_collection",
+			src:  "a",
+			dst:  "b",
+			in:   map[string]interface{}{"a": []interface{}{42}, "b": 1},
+			out:  map[string]interface{}{"a.#": 1, "a.0": 42, "b": 1},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			res, _ := Flatten(tc.in, DefaultTokenizer)
+
+			res.Append(tc.src, tc.dst)
+			if !reflect.DeepEqual(res.m, tc.out) {
+				t.Errorf("unexpected result (%s -> %s):\n%+v\n%+v", tc.src, tc.dst, res.m, tc.out)
+			}
+		})
+	}
+}
+
 func TestMap_Expand(t *testing.T) {
 	m, err := newMap(DefaultTokenizer)
 	if err != nil {
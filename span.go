@@ -0,0 +1,289 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flatex
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/starvn/flatex/internal/span"
+)
+
+func isSpan(k string) bool {
+	_, ok := span.Parse(k, 0)
+	return ok
+}
+
+// lastSpan returns the position of the last segment that may be a negative
+// index or a slice segment, or -1 if there is none
+func lastSpan(ks []string) int {
+	for i := len(ks) - 1; i >= 0; i-- {
+		if isSpan(ks[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// spanMatch is a concrete path produced by resolving the negative indices,
+// the slice segments and the wildcards preceding them in a pattern
+type spanMatch struct {
+	keys []string
+	// element is set when the last segment of the pattern selected an
+	// element of a collection
+	element bool
+	single  bool
+}
+
+// expandSpans resolves the first upTo segments of the given pattern against
+// the keys of the map. Span-like segments are taken literally when their
+// prefix is not a collection.
+func (m *Map) expandSpans(ks []string, upTo int) []spanMatch {
+	var res []spanMatch
+	last := len(ks) - 1
+
+	var walk func(i int, acc []string, element, single bool)
+	walk = func(i int, acc []string, element, single bool) {
+		if i == upTo {
+			keys := make([]string, 0, len(ks))
+			keys = append(append(keys, acc...), ks[i:]...)
+			res = append(res, spanMatch{keys: keys, element: element, single: single})
+			return
+		}
+
+		acc = acc[:i:i]
+
+		if ks[i] == "*" {
+			for _, k := range m.children(acc) {
+				walk(i+1, append(acc, k), false, false)
+			}
+			return
+		}
+
+		if size, ok := m.size(acc); ok {
+			if s, ok := span.Parse(ks[i], size); ok {
+				for k := s.Lo; k < s.Hi; k++ {
+					walk(i+1, append(acc, strconv.Itoa(k)), i == last, s.Single)
+				}
+				return
+			}
+		}
+
+		walk(i+1, append(acc, ks[i]), false, false)
+	}
+	walk(0, make([]string, 0, len(ks)), false, false)
+
+	return res
+}
+
+// spanTarget builds the destination of a match by replacing the wildcards of
+// dst with the segments matched by the wildcards of src, and the span
+// segments repeated at the same position with the resolved indices
+func (m *Map) spanTarget(src, dst []string, upTo int, keys []string) []string {
+	var stars []string
+	for i, k := range src[:upTo] {
+		if k == "*" {
+			stars = append(stars, keys[i])
+		}
+	}
+
+	res := make([]string, len(dst))
+	for i, k := range dst {
+		switch {
+		case k == "*" && len(stars) > 0:
+			res[i], stars = stars[0], stars[1:]
+		case i < upTo && src[i] == k && isSpan(k):
+			res[i] = keys[i]
+		default:
+			res[i] = k
+		}
+	}
+	return res
+}
+
+func (m *Map) delSpans(ks []string) {
+	removed := map[string][]int{}
+
+	for _, s := range m.expandSpans(ks, lastSpan(ks)+1) {
+		if !s.element {
+			m.del(m.t.Token(s.keys))
+			continue
+		}
+		last := len(s.keys) - 1
+		i, _ := strconv.Atoi(s.keys[last])
+		prefix := m.t.Token(s.keys[:last])
+		removed[prefix] = append(removed[prefix], i)
+	}
+
+	for prefix, is := range removed {
+		m.compact(prefix, is)
+	}
+}
+
+func (m *Map) moveSpans(src, dst []string) {
+	upTo := lastSpan(src) + 1
+	sep := m.t.Separator()
+	removed := map[string][]int{}
+	collected := map[string]int{}
+
+	for _, s := range m.expandSpans(src, upTo) {
+		original := m.t.Token(s.keys)
+		newKey := m.t.Token(m.spanTarget(src, dst, upTo, s.keys))
+
+		if !s.element {
+			m.move(original, newKey)
+			continue
+		}
+
+		last := len(s.keys) - 1
+		i, _ := strconv.Atoi(s.keys[last])
+		prefix := m.t.Token(s.keys[:last])
+		removed[prefix] = append(removed[prefix], i)
+
+		if s.single {
+			m.move(original, newKey)
+			continue
+		}
+
+		// the elements selected by a slice segment are collected into a new
+		// collection at the destination
+		n := collected[newKey]
+		collected[newKey] = n + 1
+		m.move(original, newKey+sep+strconv.Itoa(n))
+	}
+
+	for k, n := range collected {
+		m.m[k+sep+"#"] = n
+	}
+
+	for prefix, is := range removed {
+		m.compact(prefix, is)
+	}
+}
+
+// size returns the number of elements of the collection at the given path
+func (m *Map) size(ks []string) (int, bool) {
+	return m.sizeOf(m.t.Token(append(ks[:len(ks):len(ks)], "#")))
+}
+
+func (m *Map) sizeOf(k string) (int, bool) {
+	v, ok := m.m[k]
+	if !ok {
+		return 0, false
+	}
+	size, ok := v.(int)
+	return size, ok
+}
+
+// children returns the labels of the elements stored under the given path
+func (m *Map) children(ks []string) []string {
+	if size, ok := m.size(ks); ok {
+		res := make([]string, size)
+		for i := range res {
+			res[i] = strconv.Itoa(i)
+		}
+		return res
+	}
+
+	sep := m.t.Separator()
+	prefix := ""
+	if len(ks) > 0 {
+		prefix = m.t.Token(ks) + sep
+	}
+
+	seen := map[string]struct{}{}
+	for k := range m.m {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		k = k[len(prefix):]
+		if i := strings.Index(k, sep); i > -1 {
+			k = k[:i]
+		}
+		seen[k] = struct{}{}
+	}
+
+	res := make([]string, 0, len(seen))
+	for k := range seen {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// elementKey splits a key of an element of the collection with the given
+// prefix (including the trailing separator) into the index of the element
+// and the remainder of the key
+func (m *Map) elementKey(prefix, k string) (int, string, bool) {
+	if !strings.HasPrefix(k, prefix) {
+		return 0, "", false
+	}
+	k = k[len(prefix):]
+	rest := ""
+	if i := strings.Index(k, m.t.Separator()); i > -1 {
+		k, rest = k[:i], k[i:]
+	}
+	i, err := strconv.Atoi(k)
+	if err != nil || i < 0 {
+		return 0, "", false
+	}
+	return i, rest, true
+}
+
+// compact deletes the given elements of the collection at prefix and
+// renumbers the remaining ones so the indices stay contiguous
+func (m *Map) compact(prefix string, removed []int) {
+	size, ok := m.sizeOf(prefix + m.t.Separator() + "#")
+	if !ok {
+		return
+	}
+
+	sort.Ints(removed)
+	j := 0
+	for _, i := range removed {
+		if i < 0 || i >= size || (j > 0 && removed[j-1] == i) {
+			continue
+		}
+		removed[j] = i
+		j++
+	}
+	removed = removed[:j]
+
+	p := prefix + m.t.Separator()
+	moved := map[string]interface{}{}
+	for k, v := range m.m {
+		i, rest, ok := m.elementKey(p, k)
+		if !ok {
+			continue
+		}
+		offset := sort.SearchInts(removed, i)
+		if offset < len(removed) && removed[offset] == i {
+			delete(m.m, k)
+			continue
+		}
+		if offset == 0 {
+			continue
+		}
+		delete(m.m, k)
+		moved[p+strconv.Itoa(i-offset)+rest] = v
+	}
+	for k, v := range moved {
+		m.m[k] = v
+	}
+	m.m[p+"#"] = size - len(removed)
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flatex
+
+import (
+	"iter"
+	"sync"
+)
+
+// SyncMap is a Map safe for concurrent use, guarded by a read-write mutex
+type SyncMap struct {
+	mu sync.RWMutex
+	m  *Map
+}
+
+// NewSyncMap wraps the map, which must not be used directly afterwards
+func NewSyncMap(m *Map) *SyncMap {
+	return &SyncMap{m: m}
+}
+
+// Snapshot returns a read-only copy of the current content of the map
+func (s *SyncMap) Snapshot() *MapSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &MapSnapshot{m: s.m.Clone()}
+}
+
+func (s *SyncMap) Move(original, newKey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.Move(original, newKey)
+}
+
+func (s *SyncMap) Del(prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.Del(prefix)
+}
+
+func (s *SyncMap) Append(src, dst string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.Append(src, dst)
+}
+
+func (s *SyncMap) RemoveAt(prefix string, i int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.RemoveAt(prefix, i)
+}
+
+func (s *SyncMap) InsertAt(prefix string, i int, value interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.InsertAt(prefix, i, value)
+}
+
+func (s *SyncMap) AppendTo(prefix string, value interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.AppendTo(prefix, value)
+}
+
+func (s *SyncMap) Expand() map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Expand()
+}
+
+// All iterates over a snapshot of the map, so the map can be changed while
+// iterating
+func (s *SyncMap) All() iter.Seq2[string, interface{}] {
+	return s.Snapshot().All()
+}
+
+// Sorted iterates over a snapshot of the map, so the map can be changed
+// while iterating
+func (s *SyncMap) Sorted() iter.Seq2[string, interface{}] {
+	return s.Snapshot().Sorted()
+}
+
+// MapSnapshot is a read-only view of the content of a SyncMap at some point
+type MapSnapshot struct {
+	m *Map
+}
+
+func (s *MapSnapshot) Expand() map[string]interface{} {
+	return s.m.Expand()
+}
+
+func (s *MapSnapshot) All() iter.Seq2[string, interface{}] {
+	return s.m.All()
+}
+
+func (s *MapSnapshot) Sorted() iter.Seq2[string, interface{}] {
+	return s.m.Sorted()
+}
+
+// Map returns a mutable copy of the snapshot
+func (s *MapSnapshot) Map() *Map {
+	return s.m.Clone()
+}
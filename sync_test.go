@@ -0,0 +1,77 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flatex
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestSyncMap(t *testing.T) {
+	m, _ := Flatten(map[string]interface{}{
+		"routes": []interface{}{},
+		"config": map[string]interface{}{"a": 1},
+	}, DefaultTokenizer)
+	s := NewSyncMap(m)
+	snapshot := s.Snapshot()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if err := s.AppendTo("routes", map[string]interface{}{"id": j}); err != nil {
+					t.Error(err)
+				}
+				k := fmt.Sprintf("config.k%d", i)
+				s.Move("config.a", k)
+				s.Move(k, "config.a")
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				s.Expand()
+				for range s.Sorted() {
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	res := s.Expand()
+	if routes := res["routes"].([]interface{}); len(routes) != 400 {
+		t.Errorf("unexpected routes: %d", len(routes))
+	}
+	if v := res["config"]; !reflect.DeepEqual(v, map[string]interface{}{"a": 1}) {
+		t.Errorf("unexpected config: %v", v)
+	}
+
+	expected := map[string]interface{}{
+		"routes": []interface{}{},
+		"config": map[string]interface{}{"a": 1},
+	}
+	if v := snapshot.Expand(); !reflect.DeepEqual(v, expected) {
+		t.Errorf("the snapshot has changed: %v", v)
+	}
+	if v := snapshot.Map().Expand(); !reflect.DeepEqual(v, expected) {
+		t.Errorf("unexpected copy: %v", v)
+	}
+}
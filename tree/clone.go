@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import "github.com/starvn/flatex/internal/deepcopy"
+
+// Clone returns a copy of the tree that can be changed without affecting the
+// original one, and without its subscriptions. The values of the leaves are
+// shared, so maps and slices stored as leaves are still the ones given to the
+// tree.
+func (t *Tree) Clone() *Tree {
+	res := *t
+	res.root = t.root.copy(nil)
+	res.subscriptions, res.tx = nil, nil
+	return &res
+}
+
+// DeepClone behaves like Clone, but it also copies the maps and slices
+// stored as leaves
+func (t *Tree) DeepClone() *Tree {
+	res := *t
+	res.root = t.root.copy(deepcopy.Copy)
+	res.subscriptions, res.tx = nil, nil
+	return &res
+}
+
+// Persistent returns an immutable copy of the tree
+func (t *Tree) Persistent() *Persistent {
+	return &Persistent{t: t.Clone()}
+}
+
+// Tree returns a mutable copy of the persistent tree
+func (p *Persistent) Tree() *Tree {
+	return p.t.Clone()
+}
+
+// copy copies the subtree, applying fn to the values of the nodes if it is
+// not nil
+func (n *node) copy(fn func(interface{}) interface{}) *node {
+	res := n.clone()
+	if fn != nil {
+		res.Value = fn(res.Value)
+	}
+	for _, e := range res.edges {
+		e.n = e.n.copy(fn)
+	}
+	return res
+}
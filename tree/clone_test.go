@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTree_Clone(t *testing.T) {
+	in := map[string]interface{}{
+		"a": []interface{}{
+			map[string]interface{}{"b": 1},
+			map[string]interface{}{"b": 2},
+		},
+		"c": map[string]interface{}{"d": []string{"x", "y"}},
+		"e": map[string]interface{}{},
+	}
+	tr, _ := New(in)
+	c := tr.Clone()
+
+	c.Add([]string{"a", "0", "x"}, true)
+	c.Move([]string{"c", "d"}, []string{"c", "z"})
+	c.Del([]string{"a", "1"})
+	if err := c.Push([]string{"a"}, 3); err != nil {
+		t.Error(err)
+	}
+
+	if v := tr.Get([]string{}); !reflect.DeepEqual(v, in) {
+		t.Errorf("the original tree has changed: %v", v)
+	}
+
+	expected := map[string]interface{}{
+		"a": []interface{}{
+			map[string]interface{}{"b": 1, "x": true},
+			3,
+		},
+		"c": map[string]interface{}{"z": []string{"x", "y"}},
+		"e": map[string]interface{}{},
+	}
+	if v := c.Get([]string{}); !reflect.DeepEqual(v, expected) {
+		t.Errorf("unexpected clone: %v", v)
+	}
+
+	// the leaves are shared
+	tr.Get([]string{"c", "d"}).([]string)[0] = "changed"
+	if v := c.Get([]string{"c", "z"}); !reflect.DeepEqual(v, []string{"changed", "y"}) {
+		t.Errorf("unexpected leaf: %v", v)
+	}
+}
+
+func TestTree_DeepClone(t *testing.T) {
+	tr, _ := New(map[string]interface{}{
+		"c": map[string]interface{}{"d": []string{"x", "y"}},
+		"e": map[string]interface{}{},
+	})
+	c := tr.DeepClone()
+
+	tr.Get([]string{"c", "d"}).([]string)[0] = "changed"
+	tr.Get([]string{"e"}).(map[string]interface{})["x"] = 1
+
+	expected := map[string]interface{}{
+		"c": map[string]interface{}{"d": []string{"x", "y"}},
+		"e": map[string]interface{}{},
+	}
+	if v := c.Get([]string{}); !reflect.DeepEqual(v, expected) {
+		t.Errorf("the clone has changed: %v", v)
+	}
+}
+
+func TestTree_Persistent(t *testing.T) {
+	in := map[string]interface{}{
+		"a": []interface{}{1, 2},
+		"c": map[string]interface{}{"d": "x"},
+	}
+	tr, _ := New(in)
+	p := tr.Persistent()
+	tr.Del([]string{"a"})
+
+	res := p.Tree()
+	res.Del([]string{"c"})
+
+	if v := p.Get([]string{}); !reflect.DeepEqual(v, in) {
+		t.Errorf("the persistent tree has changed: %v", v)
+	}
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"errors"
+)
+
+var (
+	// ErrNotFound is returned when the given path does not exist
+	ErrNotFound = errors.New("path not found")
+	// ErrNotACollection is returned by the collection operations when the
+	// given path is not a collection
+	ErrNotACollection = errors.New("not a collection")
+	// ErrIndexOutOfRange is returned when the given position is not in the
+	// collection
+	ErrIndexOutOfRange = errors.New("index out of range")
+)
+
+// InsertAt inserts v into the collection at the given path, so it ends up in
+// the position i. Negative positions count from the end of the collection.
+func (t *Tree) InsertAt(ks []string, i int, v interface{}) error {
+	if v == nil {
+		return errNoNilValuesAllowed
+	}
+
+	n, err := t.collection(ks)
+	if err != nil {
+		return err
+	}
+
+	if i < 0 {
+		i += len(n.edges)
+	}
+	if i < 0 || i > len(n.edges) {
+		return ErrIndexOutOfRange
+	}
+
+	t.observe(func() { n.insertAt(i, v) }, ks)
+	return nil
+}
+
+// RemoveAt removes the element in the position i of the collection at the
+// given path and returns its value. Negative positions count from the end of
+// the collection.
+func (t *Tree) RemoveAt(ks []string, i int) (interface{}, error) {
+	n, err := t.collection(ks)
+	if err != nil {
+		return nil, err
+	}
+
+	if i < 0 {
+		i += len(n.edges)
+	}
+	if i < 0 || i >= len(n.edges) {
+		return nil, ErrIndexOutOfRange
+	}
+
+	v := n.edges[i].n.Get()
+	t.observe(func() { n.removeEdges(i, i+1) }, ks)
+	return v, nil
+}
+
+// Push appends v to the collection at the given path
+func (t *Tree) Push(ks []string, v interface{}) error {
+	if v == nil {
+		return errNoNilValuesAllowed
+	}
+
+	n, err := t.collection(ks)
+	if err != nil {
+		return err
+	}
+
+	t.observe(func() { n.insertAt(len(n.edges), v) }, ks)
+	return nil
+}
+
+// Pop removes the last element of the collection at the given path and
+// returns its value
+func (t *Tree) Pop(ks []string) (interface{}, error) {
+	return t.RemoveAt(ks, -1)
+}
+
+// Reindex relabels the elements of the collection at the given path with
+// their positions
+func (t *Tree) Reindex(ks []string) error {
+	n, err := t.collection(ks)
+	if err != nil {
+		return err
+	}
+
+	n.reindex()
+	return nil
+}
+
+// collection returns the collection at the given path, copying the nodes
+// leading to it while a transaction is in progress, as the callers change it
+func (t *Tree) collection(ks []string) (*node, error) {
+	if t.tx != nil {
+		t.tx.c.path(t.root, ks)
+	}
+	n := t.root.find(ks...)
+	if n == nil {
+		return nil, ErrNotFound
+	}
+	if !n.isCollection {
+		return nil, ErrNotACollection
+	}
+	return n, nil
+}
+
+func (n *node) insertAt(i int, v interface{}) {
+	child := newNode()
+	child.flatten(v)
+	n.insertEdge(i, &edge{n: child})
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTree_collection(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		op   func(*Tree) (interface{}, error)
+		res  interface{}
+		err  error
+		out  interface{}
+	}{
+		{
+			name: "insert_first",
+			op: func(tr *Tree) (interface{}, error) {
+				return nil, tr.InsertAt([]string{"a"}, 0, "x")
+			},
+			out: []interface{}{"x", "b", "c"},
+		},
+		{
+			name: "insert_middle",
+			op: func(tr *Tree) (interface{}, error) {
+				return nil, tr.InsertAt([]string{"a"}, 1, map[string]interface{}{"x": 1})
+			},
+			out: []interface{}{"b", map[string]interface{}{"x": 1}, "c"},
+		},
+		{
+			name: "insert_negative",
+			op: func(tr *Tree) (interface{}, error) {
+				return nil, tr.InsertAt([]string{"a"}, -1, "x")
+			},
+			out: []interface{}{"b", "x", "c"},
+		},
+		{
+			name: "insert_out_of_range",
+			op: func(tr *Tree) (interface{}, error) {
+				return nil, tr.InsertAt([]string{"a"}, 3, "x")
+			},
+			err: ErrIndexOutOfRange,
+			out: []interface{}{"b", "c"},
+		},
+		{
+			name: "insert_into_object",
+			op: func(tr *Tree) (interface{}, error) {
+				return nil, tr.InsertAt([]string{}, 0, "x")
+			},
+			err: ErrNotACollection,
+			out: []interface{}{"b", "c"},
+		},
+		{
+			name: "remove",
+			op: func(tr *Tree) (interface{}, error) {
+				return tr.RemoveAt([]string{"a"}, 0)
+			},
+			res: "b",
+			out: []interface{}{"c"},
+		},
+		{
+			name: "remove_unknown",
+			op: func(tr *Tree) (interface{}, error) {
+				return tr.RemoveAt([]string{"b"}, 0)
+			},
+			err: ErrNotFound,
+			out: []interface{}{"b", "c"},
+		},
+		{
+			name: "push",
+			op: func(tr *Tree) (interface{}, error) {
+				return nil, tr.Push([]string{"a"}, "x")
+			},
+			out: []interface{}{"b", "c", "x"},
+		},
+		{
+			name: "pop",
+			op: func(tr *Tree) (interface{}, error) {
+				return tr.Pop([]string{"a"})
+			},
+			res: "c",
+			out: []interface{}{"b"},
+		},
+		{
+			name: "add_to_collection",
+			op: func(tr *Tree) (interface{}, error) {
+				tr.Add([]string{"a", "7"}, "x")
+				return nil, nil
+			},
+			out: []interface{}{"b", "c", "x"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr, _ := New(map[string]interface{}{"a": []interface{}{"b", "c"}})
+
+			res, err := tc.op(tr)
+			if err != tc.err {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(res, tc.res) {
+				t.Errorf("unexpected result: %v", res)
+			}
+			if out := tr.Get([]string{"a"}); !reflect.DeepEqual(out, tc.out) {
+				t.Errorf("unexpected collection: %v", out)
+			}
+		})
+	}
+}
+
+func TestTree_collection_labels(t *testing.T) {
+	tr, _ := New(map[string]interface{}{"a": []interface{}{0, 1, 2, 3}})
+
+	tr.Del([]string{"a", "1"})
+	if v := tr.Get([]string{"a", "1"}); v != 2 {
+		t.Errorf("unexpected value after Del: %v", v)
+	}
+
+	if _, err := tr.RemoveAt([]string{"a"}, 0); err != nil {
+		t.Error(err)
+	}
+	if v := tr.Get([]string{"a", "0"}); v != 2 {
+		t.Errorf("unexpected value after RemoveAt: %v", v)
+	}
+
+	if err := tr.InsertAt([]string{"a"}, 0, 42); err != nil {
+		t.Error(err)
+	}
+	if v := tr.Get([]string{"a", "1"}); v != 2 {
+		t.Errorf("unexpected value after InsertAt: %v", v)
+	}
+
+	for len(tr.root.edges[0].n.edges) > 0 {
+		if _, err := tr.Pop([]string{"a"}); err != nil {
+			t.Error(err)
+		}
+	}
+	if v := tr.Get([]string{"a"}); !reflect.DeepEqual(v, []interface{}{}) {
+		t.Errorf("unexpected empty collection: %v", v)
+	}
+	if _, err := tr.Pop([]string{"a"}); err != ErrIndexOutOfRange {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
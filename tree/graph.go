@@ -0,0 +1,113 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// collectionLabel is the label of the edge between a collapsed collection
+// and its representative element
+const collectionLabel = "[]"
+
+// WriteDOT writes the structure of the tree into w as a Graphviz digraph.
+// Collections are collapsed into their first element and the leaves are
+// annotated with the type of their values.
+func (t *Tree) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("digraph tree {\n")
+	t.root.graph(func(id int, label string) {
+		fmt.Fprintf(bw, "\tn%d [label=%s];\n", id, strconv.Quote(label))
+	}, func(from, to int, label string) {
+		fmt.Fprintf(bw, "\tn%d -> n%d [label=%s];\n", from, to, strconv.Quote(label))
+	})
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+// WriteMermaid writes the structure of the tree into w as a Mermaid
+// flowchart. Collections are collapsed into their first element and the
+// leaves are annotated with the type of their values.
+func (t *Tree) WriteMermaid(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("graph TD\n")
+	t.root.graph(func(id int, label string) {
+		fmt.Fprintf(bw, "\tn%d[\"%s\"]\n", id, mermaidEscape(label))
+	}, func(from, to int, label string) {
+		fmt.Fprintf(bw, "\tn%d -->|\"%s\"| n%d\n", from, mermaidEscape(label), to)
+	})
+	return bw.Flush()
+}
+
+// graph visits the structure of the subtree, declaring every vertex before
+// the edges reaching it
+func (n *node) graph(vertex func(id int, label string), link func(from, to int, label string)) {
+	next := 0
+	var visit func(n *node) int
+	visit = func(n *node) int {
+		id := next
+		next++
+		vertex(id, n.kind())
+
+		if n.isCollection {
+			if len(n.edges) > 0 {
+				link(id, visit(n.edges[0].n), collectionLabel)
+			}
+			return id
+		}
+
+		for _, e := range n.edges {
+			link(id, visit(e.n), e.label)
+		}
+		return id
+	}
+	visit(n)
+}
+
+// kind describes the type of the content of the node
+func (n *node) kind() string {
+	switch {
+	case n.isCollection:
+		return "array"
+	case !n.IsLeaf():
+		return "object"
+	}
+
+	switch v := n.Value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+		return "number"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
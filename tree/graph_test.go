@@ -0,0 +1,103 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTree_WriteDOT(t *testing.T) {
+	tr, _ := New(map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"price": 1.5, "name": "x"},
+			map[string]interface{}{"price": 2.5, "name": "y", "extra": true},
+		},
+		"next":  nil,
+		"tags":  []interface{}{},
+		`"odd"`: map[string]interface{}{},
+	})
+	tr.Sort()
+
+	sb := new(strings.Builder)
+	if err := tr.WriteDOT(sb); err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := `digraph tree {
+	n0 [label="object"];
+	n1 [label="object"];
+	n0 -> n1 [label="\"odd\""];
+	n2 [label="array"];
+	n3 [label="object"];
+	n4 [label="string"];
+	n3 -> n4 [label="name"];
+	n5 [label="number"];
+	n3 -> n5 [label="price"];
+	n2 -> n3 [label="[]"];
+	n0 -> n2 [label="items"];
+	n6 [label="null"];
+	n0 -> n6 [label="next"];
+	n7 [label="array"];
+	n0 -> n7 [label="tags"];
+}
+`
+	if out := sb.String(); out != expected {
+		t.Errorf("unexpected result:\n%s", out)
+	}
+}
+
+func TestTree_WriteMermaid(t *testing.T) {
+	tr, _ := New(map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"price": 1.5, "name": "x"},
+			map[string]interface{}{"price": 2.5, "name": "y", "extra": true},
+		},
+		"next":  nil,
+		"tags":  []interface{}{},
+		`"odd"`: map[string]interface{}{},
+	})
+	tr.Sort()
+
+	sb := new(strings.Builder)
+	if err := tr.WriteMermaid(sb); err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := `graph TD
+	n0["object"]
+	n1["object"]
+	n0 -->|"#quot;odd#quot;"| n1
+	n2["array"]
+	n3["object"]
+	n4["string"]
+	n3 -->|"name"| n4
+	n5["number"]
+	n3 -->|"price"| n5
+	n2 -->|"[]"| n3
+	n0 -->|"items"| n2
+	n6["null"]
+	n0 -->|"next"| n6
+	n7["array"]
+	n0 -->|"tags"| n7
+`
+	if out := sb.String(); out != expected {
+		t.Errorf("unexpected result:\n%s", out)
+	}
+}
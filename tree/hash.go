@@ -0,0 +1,177 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+const (
+	fnvOffset = 14695981039346656037
+	fnvPrime  = 1099511628211
+)
+
+// Hash returns the hash of the content under the given path and reports if
+// the path exists. Equal contents have equal hashes whatever the order of
+// the keys of their objects, so storing the hash of a path is enough to tell
+// later if anything under it has changed. The hash of a Tree is computed on
+// every call, as its nodes can change at any time.
+func (t *Tree) Hash(ks []string) (uint64, bool) {
+	n := t.root.find(ks...)
+	if n == nil {
+		return 0, false
+	}
+	return n.hash(false), true
+}
+
+// Equal reports if both trees hold the same document, comparing them node by
+// node and skipping the subtrees they share
+func (t *Tree) Equal(other *Tree) bool {
+	return t.root.equal(other.root)
+}
+
+// Hash behaves like Tree.Hash, but the hash of every node is kept once it is
+// computed, as the nodes of a Persistent never change. Hashing a version
+// derived from a hashed one only hashes the nodes the change copied.
+func (p *Persistent) Hash(ks []string) (uint64, bool) {
+	n := p.t.root.find(ks...)
+	if n == nil {
+		return 0, false
+	}
+	return n.hash(true), true
+}
+
+// Equal behaves like Tree.Equal, but the versions with different hashes are
+// told apart without comparing their nodes
+func (p *Persistent) Equal(other *Persistent) bool {
+	if p.t.root == other.t.root {
+		return true
+	}
+	return p.t.root.hash(true) == other.t.root.hash(true) && p.t.root.equal(other.t.root)
+}
+
+// hash returns the FNV-1a hash of the subtree. Collections combine the
+// hashes of their elements in order, while objects add up the hashes of
+// their labels and children so the order of the keys does not matter. The
+// hashes are kept in the nodes and reused if keep is true, which is only
+// safe for nodes that never change.
+func (n *node) hash(keep bool) uint64 {
+	if keep {
+		if h := n.sum.Load(); h != 0 {
+			return h
+		}
+	}
+
+	h := uint64(fnvOffset)
+	switch {
+	case len(n.edges) == 0:
+		h = hashValue(fnvByte(h, 'v'), n.Value)
+	case n.isCollection:
+		h = fnvByte(h, 'c')
+		for _, e := range n.edges {
+			h = fnvUint64(h, e.n.hash(keep))
+		}
+	default:
+		var sum uint64
+		for _, e := range n.edges {
+			sum += fnvUint64(fnvByte(fnvString(fnvOffset, e.label), 0), e.n.hash(keep))
+		}
+		h = fnvUint64(fnvByte(h, 'o'), sum)
+	}
+
+	if keep {
+		n.sum.Store(h)
+	}
+	return h
+}
+
+func hashValue(h uint64, v interface{}) uint64 {
+	switch v := v.(type) {
+	case nil:
+		return fnvByte(h, 'n')
+	case bool:
+		if v {
+			return fnvByte(fnvByte(h, 'b'), 1)
+		}
+		return fnvByte(fnvByte(h, 'b'), 0)
+	case string:
+		return fnvString(fnvByte(h, 's'), v)
+	case float64:
+		return fnvUint64(fnvByte(h, 'f'), math.Float64bits(v))
+	case int:
+		return fnvUint64(fnvByte(h, 'i'), uint64(v))
+	case int64:
+		return fnvUint64(fnvByte(h, 'l'), uint64(v))
+	case json.Number:
+		return fnvString(fnvByte(h, 'j'), string(v))
+	}
+	return fnvString(fnvByte(h, '?'), fmt.Sprintf("%T:%v", v, v))
+}
+
+func fnvByte(h uint64, b byte) uint64 {
+	return (h ^ uint64(b)) * fnvPrime
+}
+
+func fnvString(h uint64, s string) uint64 {
+	for i := 0; i < len(s); i++ {
+		h = fnvByte(h, s[i])
+	}
+	return h
+}
+
+func fnvUint64(h, v uint64) uint64 {
+	for i := 0; i < 8; i++ {
+		h = fnvByte(h, byte(v>>(8*i)))
+	}
+	return h
+}
+
+// equal reports if both subtrees hold the same content
+func (n *node) equal(o *node) bool {
+	if n == o {
+		return true
+	}
+	if len(n.edges) != len(o.edges) {
+		return false
+	}
+	if len(n.edges) == 0 {
+		return reflect.DeepEqual(n.Value, o.Value)
+	}
+	if n.isCollection != o.isCollection {
+		return false
+	}
+
+	if n.isCollection {
+		for i, e := range n.edges {
+			if !e.n.equal(o.edges[i].n) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, e := range n.edges {
+		other := o.child(e.label)
+		if other == nil || !e.n.equal(other.n) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,169 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"testing"
+)
+
+func TestTree_Equal(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		a, b  map[string]interface{}
+		equal bool
+	}{
+		{
+			name:  "same",
+			a:     map[string]interface{}{"a": []interface{}{1, "b", true, nil}, "c": map[string]interface{}{}},
+			b:     map[string]interface{}{"a": []interface{}{1, "b", true, nil}, "c": map[string]interface{}{}},
+			equal: true,
+		},
+		{
+			name:  "different_value",
+			a:     map[string]interface{}{"a": map[string]interface{}{"b": 1}},
+			b:     map[string]interface{}{"a": map[string]interface{}{"b": 2}},
+			equal: false,
+		},
+		{
+			name:  "different_type",
+			a:     map[string]interface{}{"a": 1},
+			b:     map[string]interface{}{"a": "1"},
+			equal: false,
+		},
+		{
+			name:  "different_order",
+			a:     map[string]interface{}{"a": []interface{}{1, 2}},
+			b:     map[string]interface{}{"a": []interface{}{2, 1}},
+			equal: false,
+		},
+		{
+			name:  "swapped_values",
+			a:     map[string]interface{}{"a": 1, "b": 2},
+			b:     map[string]interface{}{"a": 2, "b": 1},
+			equal: false,
+		},
+		{
+			name:  "empty_collection_and_object",
+			a:     map[string]interface{}{"a": []interface{}{}},
+			b:     map[string]interface{}{"a": map[string]interface{}{}},
+			equal: false,
+		},
+		{
+			name:  "extra_key",
+			a:     map[string]interface{}{"a": 1},
+			b:     map[string]interface{}{"a": 1, "b": 1},
+			equal: false,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			a, _ := New(tc.a)
+			b, _ := New(tc.b)
+			if res := a.Equal(b); res != tc.equal {
+				t.Errorf("unexpected result: %v", res)
+			}
+			ha, _ := a.Hash([]string{})
+			hb, _ := b.Hash([]string{})
+			if (ha == hb) != tc.equal {
+				t.Errorf("unexpected hashes: %d %d", ha, hb)
+			}
+		})
+	}
+}
+
+func TestTree_Equal_keyOrder(t *testing.T) {
+	a, _ := New(map[string]interface{}{"x": 1})
+	a.Add([]string{"a"}, 1)
+	a.Add([]string{"b"}, 2)
+	b, _ := New(map[string]interface{}{"x": 1})
+	b.Add([]string{"b"}, 2)
+	b.Add([]string{"a"}, 1)
+
+	if !a.Equal(b) {
+		t.Error("the trees differ")
+	}
+}
+
+func TestTree_Hash(t *testing.T) {
+	tr, _ := New(map[string]interface{}{
+		"a": map[string]interface{}{"b": map[string]interface{}{"c": 1}},
+		"d": 1,
+	})
+	before, ok := tr.Hash([]string{"a", "b"})
+	if !ok {
+		t.Error("missing path")
+	}
+
+	tr.Add([]string{"d"}, 2)
+	tr.Add([]string{"a", "x"}, 2)
+	if h, _ := tr.Hash([]string{"a", "b"}); h != before {
+		t.Error("the hash has changed")
+	}
+
+	tr.Add([]string{"a", "b", "c"}, 2)
+	if h, _ := tr.Hash([]string{"a", "b"}); h == before {
+		t.Error("the hash has not changed")
+	}
+
+	if _, ok := tr.Hash([]string{"a", "z"}); ok {
+		t.Error("unexpected path")
+	}
+}
+
+func TestPersistent_Equal(t *testing.T) {
+	p, _ := NewPersistent(map[string]interface{}{
+		"a": []interface{}{map[string]interface{}{"b": 1}},
+		"i": 42,
+	})
+	res := p.Add([]string{"i"}, 42)
+	if !p.Equal(res) {
+		t.Error("the trees differ")
+	}
+	if p.Equal(res.Del([]string{"i"})) {
+		t.Error("the trees are equal")
+	}
+}
+
+func TestPersistent_Hash(t *testing.T) {
+	in := map[string]interface{}{
+		"a": map[string]interface{}{"b": []interface{}{1, 2}, "c": "d"},
+		"e": true,
+	}
+	p, _ := NewPersistent(in)
+	tr, _ := New(in)
+
+	for i, ks := range [][]string{{}, {"a"}, {"a", "b"}} {
+		h, _ := p.Hash(ks)
+		expected, _ := tr.Hash(ks)
+		if h != expected {
+			t.Errorf("%d: unexpected hash: %d", i, h)
+		}
+	}
+
+	before, _ := p.Hash([]string{"a", "b"})
+	res := p.Add([]string{"a", "c"}, "x")
+	if h, _ := res.Hash([]string{"a", "b"}); h != before {
+		t.Error("the hash of the shared subtree has changed")
+	}
+	tr.Add([]string{"a", "c"}, "x")
+	h, _ := res.Hash([]string{})
+	if expected, _ := tr.Hash([]string{}); h != expected {
+		t.Errorf("unexpected hash: %d", h)
+	}
+	if res.Equal(p) {
+		t.Error("the trees are equal")
+	}
+}
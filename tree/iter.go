@@ -0,0 +1,95 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"iter"
+	"slices"
+
+	"github.com/starvn/flatex/internal/span"
+)
+
+// Leaves returns an iterator over the paths and the values of the leaves of
+// the tree. Empty collections and objects are leaves too. The tree must not
+// be modified while iterating.
+func (t *Tree) Leaves() iter.Seq2[[]string, interface{}] {
+	return func(yield func([]string, interface{}) bool) {
+		if t.root.IsLeaf() {
+			yield([]string{}, t.root.Value)
+			return
+		}
+		t.root.leaves(make([]string, 0, 8), yield)
+	}
+}
+
+// Paths returns an iterator over the concrete paths matching the pattern and
+// their values. Wildcards, negative indices and slice segments are expanded
+// lazily and the missing matches are skipped. The tree must not be modified
+// while iterating.
+func (t *Tree) Paths(ks []string) iter.Seq2[[]string, interface{}] {
+	return func(yield func([]string, interface{}) bool) {
+		t.root.paths(make([]string, 0, len(ks)), ks, yield)
+	}
+}
+
+func (n *node) leaves(path []string, yield func([]string, interface{}) bool) bool {
+	for _, e := range n.edges {
+		p := append(path, e.label)
+		if e.n.IsLeaf() {
+			if !yield(slices.Clone(p), e.n.Value) {
+				return false
+			}
+			continue
+		}
+		if !e.n.leaves(p, yield) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *node) paths(path, ks []string, yield func([]string, interface{}) bool) bool {
+	if len(ks) == 0 {
+		return yield(slices.Clone(path), n.expand())
+	}
+
+	if ks[0] != wildcard {
+		if e := n.child(ks[0]); e != nil {
+			return e.n.paths(append(path, e.label), ks[1:], yield)
+		}
+		if !n.isCollection {
+			return true
+		}
+		s, ok := span.Parse(ks[0], len(n.edges))
+		if !ok {
+			return true
+		}
+		for _, e := range n.edges[s.Lo:s.Hi] {
+			if !e.n.paths(append(path, e.label), ks[1:], yield) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, e := range n.edges {
+		if !e.n.paths(append(path, e.label), ks[1:], yield) {
+			return false
+		}
+	}
+	return true
+}
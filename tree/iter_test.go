@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTree_Leaves(t *testing.T) {
+	tr, _ := New(map[string]interface{}{
+		"a": []interface{}{
+			map[string]interface{}{"b": []interface{}{map[string]interface{}{"c": 1}}},
+			map[string]interface{}{"x": 2},
+		},
+		"e": map[string]interface{}{},
+		"f": "g",
+	})
+	tr.Sort()
+
+	var res []string
+	for k, v := range tr.Leaves() {
+		res = append(res, fmt.Sprintf("%s=%v", strings.Join(k, "."), v))
+	}
+
+	expected := []string{"a.0.b.0.c=1", "a.1.x=2", "e=map[]", "f=g"}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("unexpected leaves: %v", res)
+	}
+
+	res = res[:0]
+	for k := range tr.Leaves() {
+		res = append(res, strings.Join(k, "."))
+		if len(res) == 2 {
+			break
+		}
+	}
+	if !reflect.DeepEqual(res, []string{"a.0.b.0.c", "a.1.x"}) {
+		t.Errorf("unexpected leaves after break: %v", res)
+	}
+}
+
+func TestTree_Paths(t *testing.T) {
+	tr, _ := New(map[string]interface{}{
+		"a": []interface{}{
+			map[string]interface{}{"b": []interface{}{map[string]interface{}{"c": 1}}},
+			map[string]interface{}{"x": 2},
+			map[string]interface{}{"b": []interface{}{map[string]interface{}{"c": 3}, map[string]interface{}{"d": 4}}},
+		},
+		"f": "g",
+	})
+
+	for _, tc := range []struct {
+		pattern string
+		out     []string
+	}{
+		{pattern: "a.*.b.*.c", out: []string{"a.0.b.0.c=1", "a.2.b.0.c=3"}},
+		{pattern: "a.*.x", out: []string{"a.1.x=2"}},
+		{pattern: "a.-1.b.-1", out: []string{"a.2.b.1=map[d:4]"}},
+		{pattern: "a.1:.*", out: []string{"a.1.x=2", "a.2.b=[map[c:3] map[d:4]]"}},
+		{pattern: "a.*.y", out: nil},
+		{pattern: "f", out: []string{"f=g"}},
+	} {
+		var res []string
+		for k, v := range tr.Paths(strings.Split(tc.pattern, ".")) {
+			res = append(res, fmt.Sprintf("%s=%v", strings.Join(k, "."), v))
+		}
+		if !reflect.DeepEqual(res, tc.out) {
+			t.Errorf("unexpected paths (%s): %v", tc.pattern, res)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/starvn/flatex/internal/jsonenc"
+)
+
+// NewFromDecoder builds a tree from the next JSON value read from the
+// decoder, keeping the keys of the objects in the order they are read. The
+// numbers are decoded as the decoder is configured to.
+func NewFromDecoder(dec *json.Decoder, opts ...Option) (*Tree, error) {
+	root, err := decodeNode(dec)
+	if err != nil {
+		return nil, err
+	}
+	if len(root.edges) == 0 && root.Value == nil {
+		return nil, errNoNilValuesAllowed
+	}
+
+	tr := &Tree{root: root}
+	for _, opt := range opts {
+		opt(tr)
+	}
+	return tr, nil
+}
+
+func decodeNode(dec *json.Decoder) (*node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	n := newNode()
+	switch tok {
+	case json.Delim('{'):
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			// the decoder only returns strings as keys
+			k := tok.(string)
+			child, err := decodeNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			n.addEdge(k, &edge{n: child})
+		}
+		if len(n.edges) == 0 {
+			n.Value = map[string]interface{}{}
+		}
+	case json.Delim('['):
+		n.isCollection = true
+		for dec.More() {
+			child, err := decodeNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			n.insertEdge(len(n.edges), &edge{n: child})
+		}
+		if len(n.edges) == 0 {
+			n.Value = []interface{}{}
+		}
+	default:
+		n.Value = tok
+		return n, nil
+	}
+
+	// the closing delimiter
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// JSONOptions customizes the output of WriteJSON
+type JSONOptions struct {
+	// SortKeys writes the keys of the objects sorted instead of in the order
+	// they were added
+	SortKeys bool
+	// Indent is repeated once per level of indentation. The output is
+	// compact if it is empty.
+	Indent string
+}
+
+// WriteJSON encodes the tree straight from its nodes, without building the
+// values Get would return
+func (t *Tree) WriteJSON(w io.Writer, opts JSONOptions) error {
+	enc := jsonenc.NewWriter(w, opts.Indent)
+	t.root.writeJSON(enc, opts.SortKeys)
+	return enc.Flush()
+}
+
+// MarshalJSON encodes the tree writing the keys of its objects in the order
+// they were added
+func (t *Tree) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := t.WriteJSON(buf, JSONOptions{}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (p *Persistent) WriteJSON(w io.Writer, opts JSONOptions) error {
+	return p.t.WriteJSON(w, opts)
+}
+
+func (p *Persistent) MarshalJSON() ([]byte, error) {
+	return p.t.MarshalJSON()
+}
+
+func (n *node) writeJSON(enc *jsonenc.Writer, sortKeys bool) {
+	if len(n.edges) == 0 {
+		enc.Value(n.Value)
+		return
+	}
+
+	if n.isCollection {
+		enc.BeginArray()
+		for _, e := range n.edges {
+			e.n.writeJSON(enc, sortKeys)
+		}
+		enc.EndArray()
+		return
+	}
+
+	edges := n.edges
+	if sortKeys {
+		edges = make([]*edge, len(n.edges))
+		copy(edges, n.edges)
+		sort.Slice(edges, func(i, j int) bool { return edges[i].label < edges[j].label })
+	}
+	enc.BeginObject()
+	for _, e := range edges {
+		enc.Key(e.label)
+		e.n.writeJSON(enc, sortKeys)
+	}
+	enc.EndObject()
+}
@@ -0,0 +1,188 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewFromDecoder(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   string
+		out  string
+		err  bool
+	}{
+		{
+			name: "ordered_keys",
+			in:   `{"z": 1, "a": {"y": [3, {"c": null, "b": true}], "x": "s"}, "m": []}`,
+			out:  `{"z":1,"a":{"y":[3,{"c":null,"b":true}],"x":"s"},"m":[]}`,
+		},
+		{
+			name: "empty_object",
+			in:   `{"a": {}}`,
+			out:  `{"a":{}}`,
+		},
+		{
+			name: "collection",
+			in:   `[{"b": 1, "a": 2}, "x"]`,
+			out:  `[{"b":1,"a":2},"x"]`,
+		},
+		{
+			name: "scalar",
+			in:   `"x"`,
+			out:  `"x"`,
+		},
+		{
+			name: "duplicated_key",
+			in:   `{"a": 1, "b": 2, "a": 3}`,
+			out:  `{"a":3,"b":2}`,
+		},
+		{
+			name: "escaped_key",
+			in:   `{"a\"b": "<c>"}`,
+			out:  `{"a\"b":"\u003cc\u003e"}`,
+		},
+		{
+			name: "null",
+			in:   `null`,
+			err:  true,
+		},
+		{
+			name: "invalid",
+			in:   `{"a": [1, }`,
+			err:  true,
+		},
+		{
+			name: "truncated",
+			in:   `{"a": [1`,
+			err:  true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr, err := NewFromDecoder(json.NewDecoder(strings.NewReader(tc.in)))
+			if tc.err {
+				if err == nil {
+					t.Error("expecting error")
+				}
+				return
+			}
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			b, err := json.Marshal(tr)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if string(b) != tc.out {
+				t.Errorf("unexpected result: %s", b)
+			}
+
+			var expected interface{}
+			if err := json.Unmarshal([]byte(tc.in), &expected); err != nil {
+				t.Error(err)
+			}
+			if v := tr.Get([]string{}); !reflect.DeepEqual(v, expected) {
+				t.Errorf("unexpected tree: %v", v)
+			}
+		})
+	}
+}
+
+func TestNewFromDecoder_operations(t *testing.T) {
+	dec := json.NewDecoder(strings.NewReader(`{"z": {"b": 1, "a": 2}, "y": [1, 2, 3]}`))
+	dec.UseNumber()
+	tr, err := NewFromDecoder(dec)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	tr.Add([]string{"x"}, true)
+	tr.Del([]string{"y", "1"})
+	tr.Move([]string{"z", "b"}, []string{"z", "c"})
+
+	b, err := tr.MarshalJSON()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if string(b) != `{"z":{"c":1,"a":2},"y":[1,3],"x":true}` {
+		t.Errorf("unexpected result: %s", b)
+	}
+	if v := tr.Get([]string{"y", "0"}); v != json.Number("1") {
+		t.Errorf("unexpected number: %#v", v)
+	}
+}
+
+func TestTree_WriteJSON(t *testing.T) {
+	in := map[string]interface{}{
+		"b": []interface{}{
+			map[string]interface{}{"y": 1.5, "x": []interface{}{}, "z": nil},
+			"<s>",
+		},
+		"a": map[string]interface{}{},
+		"c": []string{"typed", "leaf"},
+	}
+	tr, _ := New(in)
+
+	for _, indent := range []string{"", "\t", "  "} {
+		buf := &bytes.Buffer{}
+		if err := tr.WriteJSON(buf, JSONOptions{SortKeys: true, Indent: indent}); err != nil {
+			t.Error(err)
+			continue
+		}
+		expected, _ := json.MarshalIndent(in, "", indent)
+		if indent == "" {
+			expected, _ = json.Marshal(in)
+		}
+		if buf.String() != string(expected) {
+			t.Errorf("unexpected result:\n%s\nexpected:\n%s", buf.String(), expected)
+		}
+	}
+}
+
+func TestTree_WriteJSON_order(t *testing.T) {
+	tr, _ := NewFromDecoder(json.NewDecoder(strings.NewReader(`{"z": 1, "a": [{"y": 2, "b": 3}]}`)))
+
+	buf := &bytes.Buffer{}
+	if err := tr.WriteJSON(buf, JSONOptions{Indent: " "}); err != nil {
+		t.Error(err)
+	}
+	expected := "{\n \"z\": 1,\n \"a\": [\n  {\n   \"y\": 2,\n   \"b\": 3\n  }\n ]\n}"
+	if buf.String() != expected {
+		t.Errorf("unexpected result:\n%s", buf.String())
+	}
+}
+
+func TestTree_WriteJSON_error(t *testing.T) {
+	tr, _ := New(map[string]interface{}{"a": math.Inf(1)})
+	if err := tr.WriteJSON(&bytes.Buffer{}, JSONOptions{}); err == nil {
+		t.Error("expecting error")
+	}
+	if _, err := json.Marshal(tr); err == nil {
+		t.Error("expecting error")
+	}
+}
@@ -0,0 +1,245 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/starvn/flatex/internal/span"
+)
+
+// ErrUnexpectedType is returned by the typed getters when the value at the
+// given path can not be converted to the requested type
+var ErrUnexpectedType = errors.New("unexpected type")
+
+// Lookup returns the value at the given path and reports if the path exists,
+// so missing paths can be told apart from null values. Wildcards and slice
+// segments collect the values as Get does, and the path exists only if it
+// does for every matched element.
+func (t *Tree) Lookup(ks []string) (interface{}, bool) {
+	return t.root.lookup(ks...)
+}
+
+// GetString returns the string at the given path
+func (t *Tree) GetString(ks []string) (string, error) {
+	v, err := t.lookupValue(ks)
+	if err != nil {
+		return "", err
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", typeError(ks, "string", v)
+	}
+	return s, nil
+}
+
+// GetInt64 returns the integer at the given path. Any numeric type is
+// accepted as long as the value is integral and fits in an int64.
+func (t *Tree) GetInt64(ks []string) (int64, error) {
+	v, err := t.lookupValue(ks)
+	if err != nil {
+		return 0, err
+	}
+
+	switch i := v.(type) {
+	case int:
+		return int64(i), nil
+	case int8:
+		return int64(i), nil
+	case int16:
+		return int64(i), nil
+	case int32:
+		return int64(i), nil
+	case int64:
+		return i, nil
+	case uint:
+		if uint64(i) <= math.MaxInt64 {
+			return int64(i), nil
+		}
+	case uint8:
+		return int64(i), nil
+	case uint16:
+		return int64(i), nil
+	case uint32:
+		return int64(i), nil
+	case uint64:
+		if i <= math.MaxInt64 {
+			return int64(i), nil
+		}
+	case float32:
+		if f := float64(i); isInt64(f) {
+			return int64(f), nil
+		}
+	case float64:
+		if isInt64(i) {
+			return int64(i), nil
+		}
+	case json.Number:
+		if n, err := i.Int64(); err == nil {
+			return n, nil
+		}
+		if f, err := i.Float64(); err == nil && isInt64(f) {
+			return int64(f), nil
+		}
+	}
+	return 0, typeError(ks, "int64", v)
+}
+
+// GetFloat returns the number at the given path as a float64
+func (t *Tree) GetFloat(ks []string) (float64, error) {
+	v, err := t.lookupValue(ks)
+	if err != nil {
+		return 0, err
+	}
+
+	f, ok := toFloat64(v)
+	if !ok {
+		return 0, typeError(ks, "float64", v)
+	}
+	return f, nil
+}
+
+// GetBool returns the boolean at the given path
+func (t *Tree) GetBool(ks []string) (bool, error) {
+	v, err := t.lookupValue(ks)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, typeError(ks, "bool", v)
+	}
+	return b, nil
+}
+
+// GetSlice returns the collection at the given path
+func (t *Tree) GetSlice(ks []string) ([]interface{}, error) {
+	v, err := t.lookupValue(ks)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := v.([]interface{})
+	if !ok {
+		return nil, typeError(ks, "[]interface{}", v)
+	}
+	return s, nil
+}
+
+// GetMap returns the object at the given path
+func (t *Tree) GetMap(ks []string) (map[string]interface{}, error) {
+	v, err := t.lookupValue(ks)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, typeError(ks, "map[string]interface{}", v)
+	}
+	return m, nil
+}
+
+// toFloat64 converts any numeric value to a float64
+func toFloat64(v interface{}) (float64, bool) {
+	switch f := v.(type) {
+	case float64:
+		return f, true
+	case float32:
+		return float64(f), true
+	case int:
+		return float64(f), true
+	case int8:
+		return float64(f), true
+	case int16:
+		return float64(f), true
+	case int32:
+		return float64(f), true
+	case int64:
+		return float64(f), true
+	case uint:
+		return float64(f), true
+	case uint8:
+		return float64(f), true
+	case uint16:
+		return float64(f), true
+	case uint32:
+		return float64(f), true
+	case uint64:
+		return float64(f), true
+	case json.Number:
+		if n, err := f.Float64(); err == nil {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+func (t *Tree) lookupValue(ks []string) (interface{}, error) {
+	v, ok := t.root.lookup(ks...)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, strings.Join(ks, "."))
+	}
+	return v, nil
+}
+
+func (n *node) lookup(ks ...string) (interface{}, bool) {
+	if len(ks) == 0 {
+		return n.expand(), true
+	}
+
+	if ks[0] == wildcard {
+		if n.IsLeaf() && !n.isCollection {
+			if _, ok := n.Value.(map[string]interface{}); !ok {
+				return nil, false
+			}
+		}
+		return lookupEdges(n.edges, ks[1:])
+	}
+
+	if e := n.child(ks[0]); e != nil {
+		return e.n.lookup(ks[1:]...)
+	}
+
+	if n.isCollection {
+		if s, ok := span.Parse(ks[0], len(n.edges)); ok && !s.Single {
+			return lookupEdges(n.edges[s.Lo:s.Hi], ks[1:])
+		}
+	}
+	return nil, false
+}
+
+func lookupEdges(edges []*edge, ks []string) (interface{}, bool) {
+	res := make([]interface{}, len(edges))
+	found := true
+	for i, e := range edges {
+		v, ok := e.n.lookup(ks...)
+		res[i] = v
+		found = found && ok
+	}
+	return res, found
+}
+
+func typeError(ks []string, want string, have interface{}) error {
+	return fmt.Errorf("%w at %s: want %s, have %T", ErrUnexpectedType, strings.Join(ks, "."), want, have)
+}
+
+func isInt64(f float64) bool {
+	return f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTree_Lookup(t *testing.T) {
+	tr, _ := New(map[string]interface{}{
+		"a": []interface{}{
+			map[string]interface{}{"b": 1, "c": nil},
+			map[string]interface{}{"c": 2},
+		},
+		"null":   nil,
+		"string": "x",
+	})
+
+	for _, tc := range []struct {
+		pattern string
+		out     interface{}
+		found   bool
+	}{
+		{pattern: "null", out: nil, found: true},
+		{pattern: "missing", out: nil, found: false},
+		{pattern: "string.x", out: nil, found: false},
+		{pattern: "a.0.c", out: nil, found: true},
+		{pattern: "a.*.c", out: []interface{}{nil, 2}, found: true},
+		{pattern: "a.*.b", out: []interface{}{1, nil}, found: false},
+		{pattern: "a.-1.c", out: 2, found: true},
+		{pattern: "a.1:.c", out: []interface{}{2}, found: true},
+		{pattern: "string.*", out: nil, found: false},
+	} {
+		v, found := tr.Lookup(strings.Split(tc.pattern, "."))
+		if found != tc.found {
+			t.Errorf("unexpected found flag (%s): %v", tc.pattern, found)
+		}
+		if !reflect.DeepEqual(v, tc.out) {
+			t.Errorf("unexpected value (%s): %v", tc.pattern, v)
+		}
+	}
+}
+
+func TestTree_typedGetters(t *testing.T) {
+	tr, _ := New(map[string]interface{}{
+		"null":   nil,
+		"string": "x",
+		"int":    42,
+		"float":  4.0,
+		"frac":   4.5,
+		"number": json.Number("12"),
+		"bool":   true,
+		"list":   []interface{}{1},
+		"object": map[string]interface{}{"d": "e"},
+	})
+
+	for _, tc := range []struct {
+		name string
+		get  func([]string) (interface{}, error)
+		path string
+		out  interface{}
+		err  error
+	}{
+		{name: "string", get: func(ks []string) (interface{}, error) { return tr.GetString(ks) }, path: "string", out: "x"},
+		{name: "string_mismatch", get: func(ks []string) (interface{}, error) { return tr.GetString(ks) }, path: "int", out: "", err: ErrUnexpectedType},
+		{name: "string_missing", get: func(ks []string) (interface{}, error) { return tr.GetString(ks) }, path: "missing", out: "", err: ErrNotFound},
+		{name: "int", get: func(ks []string) (interface{}, error) { return tr.GetInt64(ks) }, path: "int", out: int64(42)},
+		{name: "int_from_float", get: func(ks []string) (interface{}, error) { return tr.GetInt64(ks) }, path: "float", out: int64(4)},
+		{name: "int_from_fraction", get: func(ks []string) (interface{}, error) { return tr.GetInt64(ks) }, path: "frac", out: int64(0), err: ErrUnexpectedType},
+		{name: "int_from_number", get: func(ks []string) (interface{}, error) { return tr.GetInt64(ks) }, path: "number", out: int64(12)},
+		{name: "int_from_null", get: func(ks []string) (interface{}, error) { return tr.GetInt64(ks) }, path: "null", out: int64(0), err: ErrUnexpectedType},
+		{name: "float", get: func(ks []string) (interface{}, error) { return tr.GetFloat(ks) }, path: "frac", out: 4.5},
+		{name: "float_from_int", get: func(ks []string) (interface{}, error) { return tr.GetFloat(ks) }, path: "int", out: 42.0},
+		{name: "float_from_number", get: func(ks []string) (interface{}, error) { return tr.GetFloat(ks) }, path: "number", out: 12.0},
+		{name: "bool", get: func(ks []string) (interface{}, error) { return tr.GetBool(ks) }, path: "bool", out: true},
+		{name: "bool_mismatch", get: func(ks []string) (interface{}, error) { return tr.GetBool(ks) }, path: "string", out: false, err: ErrUnexpectedType},
+		{name: "slice", get: func(ks []string) (interface{}, error) { return tr.GetSlice(ks) }, path: "list", out: []interface{}{1}},
+		{name: "slice_mismatch", get: func(ks []string) (interface{}, error) { return tr.GetSlice(ks) }, path: "object", out: []interface{}(nil), err: ErrUnexpectedType},
+		{name: "map", get: func(ks []string) (interface{}, error) { return tr.GetMap(ks) }, path: "object", out: map[string]interface{}{"d": "e"}},
+		{name: "map_mismatch", get: func(ks []string) (interface{}, error) { return tr.GetMap(ks) }, path: "list", out: map[string]interface{}(nil), err: ErrUnexpectedType},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := tc.get(strings.Split(tc.path, "."))
+			if !errors.Is(err, tc.err) {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(v, tc.out) {
+				t.Errorf("unexpected value: %#v", v)
+			}
+		})
+	}
+
+	_, err := tr.GetInt64([]string{"string"})
+	if err == nil || err.Error() != "unexpected type at string: want int64, have string" {
+		t.Errorf("unexpected error message: %v", err)
+	}
+}
@@ -19,6 +19,9 @@ package tree
 import (
 	"sort"
 	"strconv"
+	"sync/atomic"
+
+	"github.com/starvn/flatex/internal/span"
 )
 
 type edge struct {
@@ -26,18 +29,27 @@ type edge struct {
 	n     *node
 }
 
-func newNode(depth int) *node {
+func newNode() *node {
 	return &node{
 		edges: []*edge{},
-		depth: depth,
 	}
 }
 
+// indexThreshold is the number of children from which the edges of an
+// object are indexed by label
+const indexThreshold = 32
+
 type node struct {
 	Value        interface{}
 	isCollection bool
 	edges        []*edge
-	depth        int
+	// index maps the labels of the edges of wide objects to the edges. It is
+	// kept up to date by every operation changing the edges, so reads never
+	// write to the node.
+	index map[string]*edge
+	// sum is the hash of the subtree, kept by the nodes of persistent trees
+	// once it is computed. It is zero until then.
+	sum atomic.Uint64
 }
 
 func (n *node) Add(ks []string, v interface{}) {
@@ -46,16 +58,83 @@ func (n *node) Add(ks []string, v interface{}) {
 		return
 	}
 
+	if e := n.child(ks[0]); e != nil {
+		e.n.Add(ks[1:], v)
+		return
+	}
+
+	child := newNode()
+	n.addEdge(ks[0], &edge{n: child})
+	child.Add(ks[1:], v)
+}
+
+// vivify behaves like Add, but the missing nodes and the null leaves followed
+// by an index are turned into collections, and the collections are padded
+// with null elements up to the index written
+func (n *node) vivify(ks []string, v interface{}) {
+	if len(ks) == 0 {
+		n.flatten(v)
+		return
+	}
+
+	if e := n.child(ks[0]); e != nil {
+		if len(ks) > 1 && isIndex(ks[1]) && len(e.n.edges) == 0 && e.n.Value == nil {
+			e.n.isCollection = true
+		}
+		e.n.vivify(ks[1:], v)
+		return
+	}
+
+	if n.isCollection {
+		if i, err := strconv.Atoi(ks[0]); err == nil {
+			for len(n.edges) < i {
+				n.addEdge(ks[0], &edge{n: newNode()})
+			}
+		}
+	}
+
+	child := newNode()
+	child.isCollection = len(ks) > 1 && isIndex(ks[1])
+	n.addEdge(ks[0], &edge{n: child})
+	child.vivify(ks[1:], v)
+}
+
+// child returns the edge with the given label. Negative indices address the
+// elements of collections from the end.
+func (n *node) child(k string) *edge {
+	if n.isCollection {
+		// the elements of a collection are labelled with their positions
+		if i, err := strconv.Atoi(k); err == nil {
+			if i >= 0 && i < len(n.edges) && n.edges[i].label == k {
+				return n.edges[i]
+			}
+			if s, ok := span.Parse(k, len(n.edges)); ok && s.Single && s.Lo < s.Hi {
+				return n.edges[s.Lo]
+			}
+			return nil
+		}
+	} else if n.index != nil {
+		return n.index[k]
+	}
+
 	for _, e := range n.edges {
-		if e.label == ks[0] {
-			e.n.Add(ks[1:], v)
-			return
+		if e.label == k {
+			return e
 		}
 	}
+	return nil
+}
 
-	child := newNode(n.depth + 1)
-	n.edges = append(n.edges, &edge{label: ks[0], n: child})
-	child.Add(ks[1:], v)
+// find returns the node at the given path, if any
+func (n *node) find(ks ...string) *node {
+	for _, k := range ks {
+		e := n.child(k)
+		if e == nil {
+			return nil
+		}
+		n = e.n
+	}
+	return n
 }
 
 func (n *node) Del(ks ...string) {
@@ -73,25 +152,32 @@ func (n *node) Del(ks ...string) {
 			return
 		}
 
-		for i := range n.edges {
-			n.edges[i] = nil
-		}
-		n.edges = n.edges[:0]
+		n.removeEdges(0, len(n.edges))
 		return
 	}
 
-	for i, e := range n.edges {
-		if e.label == ks[0] {
-			if lenKs == 1 {
-				copy(n.edges[i:], n.edges[i+1:])
-				n.edges[len(n.edges)-1] = nil
-				n.edges = n.edges[:len(n.edges)-1]
+	if n.isCollection {
+		if s, ok := span.Parse(ks[0], len(n.edges)); ok {
+			if lenKs > 1 {
+				for _, e := range n.edges[s.Lo:s.Hi] {
+					e.n.Del(ks[1:]...)
+				}
 				return
 			}
-			e.n.Del(ks[1:]...)
+			n.removeEdges(s.Lo, s.Hi)
 			return
 		}
 	}
+
+	e := n.child(ks[0])
+	if e == nil {
+		return
+	}
+	if lenKs == 1 {
+		n.removeEdge(e)
+		return
+	}
+	e.n.Del(ks[1:]...)
 }
 
 func (n *node) Get(ks ...string) interface{} {
@@ -114,22 +200,218 @@ func (n *node) Get(ks ...string) interface{} {
 		return res
 	}
 
-	for _, e := range n.edges {
-		if e.label == ks[0] {
-			return e.n.Get(ks[1:]...)
+	if n.isCollection {
+		if s, ok := span.Parse(ks[0], lenEdges); ok {
+			if s.Single {
+				if s.Lo == s.Hi {
+					return nil
+				}
+				return n.edges[s.Lo].n.Get(ks[1:]...)
+			}
+			res := make([]interface{}, s.Hi-s.Lo)
+			for i, e := range n.edges[s.Lo:s.Hi] {
+				res[i] = e.n.Get(ks[1:]...)
+			}
+			return res
 		}
 	}
+
+	if e := n.child(ks[0]); e != nil {
+		return e.n.Get(ks[1:]...)
+	}
 	return nil
 }
 
-func (n *node) Depth() int {
-	return n.depth
+// ensure returns the parent of the node at the given path, creating the
+// missing intermediate nodes. The new nodes are collections when the segment
+// following them is an index or an append segment, which always adds a new
+// element to its collection.
+func (n *node) ensure(ks []string) *node {
+	for i, k := range ks[:len(ks)-1] {
+		if k != appendLabel {
+			if e := n.child(k); e != nil {
+				n = e.n
+				continue
+			}
+		}
+		child := newNode()
+		child.isCollection = isIndex(ks[i+1]) || ks[i+1] == appendLabel
+		n.addEdge(k, &edge{n: child})
+		n = child
+	}
+	return n
+}
+
+// accepts reports if the given path can be created under the node, which is
+// not possible when an append segment points to an object with children
+func (n *node) accepts(ks []string) bool {
+	for _, k := range ks {
+		if k == appendLabel {
+			return n.isCollection || len(n.edges) == 0
+		}
+		e := n.child(k)
+		if e == nil {
+			return true
+		}
+		n = e.n
+	}
+	return true
+}
+
+// setEdge attaches e to the node with the given label, even if it is
+// already one of its edges. Objects replace the edge already using the
+// label, while collections insert e in the position given by the label or
+// append it when the label is not an index.
+func (n *node) setEdge(label string, e *edge) {
+	pos := -1
+	for i, current := range n.edges {
+		if current == e {
+			pos = i
+			break
+		}
+	}
+
+	if pos == -1 {
+		n.addEdge(label, e)
+		return
+	}
+
+	if n.isCollection {
+		n.removeEdges(pos, pos+1)
+		n.addEdge(label, e)
+		return
+	}
+
+	if e.label == label {
+		return
+	}
+	if current := n.child(label); current != nil {
+		n.removeEdge(current)
+	}
+	n.unindex(e)
+	e.label = label
+	n.indexEdge(e)
+}
+
+// addEdge attaches a new edge to the node with the given label. Objects
+// replace the edge already using the label, while collections insert e in
+// the position given by the label or append it when the label is not an
+// index.
+func (n *node) addEdge(label string, e *edge) {
+	if n.isCollection || (label == appendLabel && len(n.edges) == 0) {
+		n.isCollection = true
+		i := len(n.edges)
+		if j, err := strconv.Atoi(label); err == nil && j >= 0 && j < i {
+			i = j
+		}
+		n.insertEdge(i, e)
+		return
+	}
+
+	n.Value = nil
+	e.label = label
+	if current := n.child(label); current != nil {
+		for i := range n.edges {
+			if n.edges[i] == current {
+				n.edges[i] = e
+				break
+			}
+		}
+		if n.index != nil {
+			n.index[label] = e
+		}
+		return
+	}
+
+	n.edges = append(n.edges, e)
+	n.indexEdge(e)
 }
 
-func (n *node) SetDepth(d int) {
-	n.depth = d
+// indexEdge adds e to the index of the object, building it once the object
+// is wide enough
+func (n *node) indexEdge(e *edge) {
+	if n.isCollection {
+		return
+	}
+	if n.index != nil {
+		n.index[e.label] = e
+		return
+	}
+	if len(n.edges) < indexThreshold {
+		return
+	}
+	n.index = make(map[string]*edge, 2*len(n.edges))
 	for _, e := range n.edges {
-		e.n.SetDepth(d + 1)
+		n.index[e.label] = e
+	}
+}
+
+func (n *node) unindex(e *edge) {
+	if n.index != nil && n.index[e.label] == e {
+		delete(n.index, e.label)
+	}
+}
+
+// insertEdge inserts e in the position i of a collection
+func (n *node) insertEdge(i int, e *edge) {
+	n.Value = nil
+	e.label = strconv.Itoa(i)
+	n.edges = append(n.edges, e)
+	if i == len(n.edges)-1 {
+		return
+	}
+	copy(n.edges[i+1:], n.edges[i:])
+	n.edges[i] = e
+	n.reindex()
+}
+
+// removeEdge detaches e from the node
+func (n *node) removeEdge(e *edge) {
+	for i, current := range n.edges {
+		if current == e {
+			n.removeEdges(i, i+1)
+			return
+		}
+	}
+}
+
+func isIndex(k string) bool {
+	i, err := strconv.Atoi(k)
+	return err == nil && i >= 0
+}
+
+// removeEdges drops the edges in the positions [lo, hi)
+func (n *node) removeEdges(lo, hi int) {
+	if lo >= hi {
+		return
+	}
+	l := len(n.edges)
+	if lo == 0 && hi == l {
+		n.index = nil
+	}
+	if n.index != nil {
+		for _, e := range n.edges[lo:hi] {
+			n.unindex(e)
+		}
+	}
+	copy(n.edges[lo:], n.edges[hi:])
+	for i := l - (hi - lo); i < l; i++ {
+		n.edges[i] = nil
+	}
+	n.edges = n.edges[:l-(hi-lo)]
+
+	if n.isCollection {
+		n.reindex()
+		if len(n.edges) == 0 {
+			n.Value = []interface{}{}
+		}
+	}
+}
+
+// reindex labels the elements of a collection with their positions
+func (n *node) reindex() {
+	for i, e := range n.edges {
+		e.label = strconv.Itoa(i)
 	}
 }
 
@@ -159,6 +441,13 @@ func (n *node) expand() interface{} {
 	return res
 }
 
+// set replaces the content of the node with v
+func (n *node) set(v interface{}) {
+	n.removeEdges(0, len(n.edges))
+	n.Value = nil
+	n.flatten(v)
+}
+
 func (n *node) flatten(i interface{}) {
 	switch v := i.(type) {
 	case map[string]interface{}:
@@ -173,6 +462,7 @@ func (n *node) flatten(i interface{}) {
 		}
 	case []interface{}:
 		n.isCollection = true
+		n.index = nil
 		if len(v) == 0 {
 			n.Value = v
 			break
@@ -0,0 +1,207 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Change describes how the value of a path matching a subscribed pattern has
+// changed. Old is nil for the paths created by the change and New is nil for
+// the deleted ones.
+type Change struct {
+	Path []string
+	Old  interface{}
+	New  interface{}
+}
+
+// Subscription receives the changes of the values matching a pattern
+type Subscription struct {
+	t       *Tree
+	pattern []string
+	fn      func(Change)
+	batch   func([]Change)
+	pending []Change
+}
+
+// Subscribe calls fn for every change of the values matching the pattern,
+// right after the operation causing it. Every operation changing the values
+// of the tree is observed, and the values are compared once it is over,
+// so the values moving inside the matches are reported as changes of the
+// matches.
+func (t *Tree) Subscribe(ks []string, fn func(Change)) *Subscription {
+	s := &Subscription{t: t, pattern: ks, fn: fn}
+	t.subscriptions = append(t.subscriptions, s)
+	return s
+}
+
+// SubscribeBatch behaves like Subscribe, but the changes are queued until
+// Flush is called
+func (t *Tree) SubscribeBatch(ks []string, fn func([]Change)) *Subscription {
+	s := &Subscription{t: t, pattern: ks, batch: fn}
+	t.subscriptions = append(t.subscriptions, s)
+	return s
+}
+
+// Flush delivers the changes queued for the batched subscriptions
+func (t *Tree) Flush() {
+	for _, s := range append([]*Subscription{}, t.subscriptions...) {
+		if len(s.pending) == 0 {
+			continue
+		}
+		changes := s.pending
+		s.pending = nil
+		s.batch(changes)
+	}
+}
+
+// Cancel stops the delivery of changes, dropping the queued ones
+func (s *Subscription) Cancel() {
+	s.pending = nil
+	subs := s.t.subscriptions
+	for i, current := range subs {
+		if current == s {
+			s.t.subscriptions = append(subs[:i:i], subs[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *Subscription) deliver(changes []Change) {
+	if s.batch != nil {
+		s.pending = append(s.pending, changes...)
+		return
+	}
+	for _, c := range changes {
+		s.fn(c)
+	}
+}
+
+// observe runs op, which changes the given paths, and reports the changes to
+// the subscriptions whose patterns overlap them
+func (t *Tree) observe(op func(), paths ...[]string) {
+	if len(t.subscriptions) == 0 && t.tx == nil {
+		op()
+		return
+	}
+
+	o := &observer{t: t}
+	for _, p := range paths {
+		o.touch(p)
+	}
+	op()
+	o.report()
+}
+
+// observer collects the subscriptions affected by a sequence of changes,
+// keeping the values matching them before the first change affecting them
+type observer struct {
+	t        *Tree
+	affected []*Subscription
+	before   [][]Match
+}
+
+// touch must be called before changing the path. The path is also recorded
+// in the transaction in progress, which changes it again if rolled back.
+func (o *observer) touch(path []string) {
+	if o.t.tx != nil {
+		o.t.tx.touch(path)
+	}
+	for _, s := range o.t.subscriptions {
+		if o.has(s) || !overlaps(s.pattern, path) {
+			continue
+		}
+		o.affected = append(o.affected, s)
+		o.before = append(o.before, o.t.FindPaths(s.pattern))
+	}
+}
+
+func (o *observer) has(s *Subscription) bool {
+	for _, current := range o.affected {
+		if current == s {
+			return true
+		}
+	}
+	return false
+}
+
+// report delivers the changes of the affected subscriptions
+func (o *observer) report() {
+	for i, s := range o.affected {
+		if changes := diff(o.before[i], o.t.FindPaths(s.pattern)); len(changes) > 0 {
+			s.deliver(changes)
+		}
+	}
+}
+
+// overlaps reports if changing the path can change the values matching the
+// pattern. Positions in collections always overlap, as inserting or removing
+// an element relabels the ones after it.
+func overlaps(pattern, path []string) bool {
+	for i := 0; i < len(pattern) && i < len(path); i++ {
+		a, b := pattern[i], path[i]
+		if a == b || a == wildcard || b == wildcard || isPosition(a) || isPosition(b) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+func isPosition(k string) bool {
+	if k == appendLabel || strings.IndexByte(k, ':') != -1 {
+		return true
+	}
+	_, err := strconv.Atoi(k)
+	return err == nil
+}
+
+// diff returns the changes between two lists of matches: the changed and
+// deleted paths in their former order followed by the created ones
+func diff(before, after []Match) []Change {
+	values := make(map[string]interface{}, len(after))
+	for _, m := range after {
+		values[pathKey(m.Path)] = m.Value
+	}
+
+	var res []Change
+	seen := make(map[string]bool, len(before))
+	for _, m := range before {
+		k := pathKey(m.Path)
+		seen[k] = true
+		v, ok := values[k]
+		if !ok {
+			res = append(res, Change{Path: m.Path, Old: m.Value})
+			continue
+		}
+		if !reflect.DeepEqual(m.Value, v) {
+			res = append(res, Change{Path: m.Path, Old: m.Value, New: v})
+		}
+	}
+	for _, m := range after {
+		if !seen[pathKey(m.Path)] {
+			res = append(res, Change{Path: m.Path, New: m.Value})
+		}
+	}
+	return res
+}
+
+func pathKey(ks []string) string {
+	return strings.Join(ks, "\x00")
+}
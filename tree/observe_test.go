@@ -0,0 +1,302 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTree_Subscribe(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		in      map[string]interface{}
+		pattern []string
+		op      func(*Tree)
+		changes []Change
+	}{
+		{
+			name: "add",
+			in: map[string]interface{}{
+				"config": map[string]interface{}{
+					"routes": []interface{}{
+						map[string]interface{}{"path": "/a"},
+						map[string]interface{}{"path": "/b"},
+					},
+					"timeout": 10,
+				},
+			},
+			pattern: []string{"config", "routes", "*"},
+			op:      func(tr *Tree) { tr.Add([]string{"config", "routes", "0", "method"}, "GET") },
+			changes: []Change{
+				{
+					Path: []string{"config", "routes", "0"},
+					Old:  map[string]interface{}{"path": "/a"},
+					New:  map[string]interface{}{"path": "/a", "method": "GET"},
+				},
+			},
+		},
+		{
+			name: "del",
+			in: map[string]interface{}{
+				"config": map[string]interface{}{
+					"routes": []interface{}{
+						map[string]interface{}{"path": "/a"},
+						map[string]interface{}{"path": "/b"},
+					},
+					"timeout": 10,
+				},
+			},
+			pattern: []string{"config", "routes", "*", "path"},
+			op:      func(tr *Tree) { tr.Del([]string{"config", "routes", "0"}) },
+			changes: []Change{
+				{Path: []string{"config", "routes", "0", "path"}, Old: "/a", New: "/b"},
+				{Path: []string{"config", "routes", "1", "path"}, Old: "/b"},
+			},
+		},
+		{
+			name: "move",
+			in: map[string]interface{}{
+				"config": map[string]interface{}{
+					"routes": []interface{}{
+						map[string]interface{}{"path": "/a"},
+						map[string]interface{}{"path": "/b"},
+					},
+					"timeout": 10,
+				},
+			},
+			pattern: []string{"config", "timeout"},
+			op:      func(tr *Tree) { tr.Move([]string{"config", "timeout"}, []string{"timeout"}) },
+			changes: []Change{
+				{Path: []string{"config", "timeout"}, Old: 10},
+			},
+		},
+		{
+			name: "move_into_pattern",
+			in: map[string]interface{}{
+				"config": map[string]interface{}{
+					"routes": []interface{}{
+						map[string]interface{}{"path": "/a"},
+						map[string]interface{}{"path": "/b"},
+					},
+					"timeout": 10,
+				},
+			},
+			pattern: []string{"config", "routes", "*"},
+			op: func(tr *Tree) {
+				tr.Add([]string{"route"}, map[string]interface{}{"path": "/c"})
+				tr.Move([]string{"route"}, []string{"config", "routes", "[]"})
+			},
+			changes: []Change{
+				{Path: []string{"config", "routes", "2"}, New: map[string]interface{}{"path": "/c"}},
+			},
+		},
+		{
+			name: "append",
+			in: map[string]interface{}{
+				"config": map[string]interface{}{
+					"routes": []interface{}{
+						map[string]interface{}{"path": "/a"},
+						map[string]interface{}{"path": "/b"},
+					},
+					"timeout": 10,
+				},
+			},
+			pattern: []string{"config", "routes"},
+			op: func(tr *Tree) {
+				tr.Add([]string{"more"}, []interface{}{"x"})
+				tr.Append([]string{"more"}, []string{"config", "routes"})
+			},
+			changes: []Change{
+				{
+					Path: []string{"config", "routes"},
+					Old: []interface{}{
+						map[string]interface{}{"path": "/a"},
+						map[string]interface{}{"path": "/b"},
+					},
+					New: []interface{}{
+						map[string]interface{}{"path": "/a"},
+						map[string]interface{}{"path": "/b"},
+						"x",
+					},
+				},
+			},
+		},
+		{
+			name: "set",
+			in: map[string]interface{}{
+				"config": map[string]interface{}{
+					"routes": []interface{}{
+						map[string]interface{}{"path": "/a"},
+						map[string]interface{}{"path": "/b"},
+					},
+					"timeout": 10,
+				},
+			},
+			pattern: []string{"config", "routes", "*", "path"},
+			op:      func(tr *Tree) { tr.Set([]string{"config", "routes", "1", "path"}, "/x") },
+			changes: []Change{
+				{Path: []string{"config", "routes", "1", "path"}, Old: "/b", New: "/x"},
+			},
+		},
+		{
+			name: "unrelated",
+			in: map[string]interface{}{
+				"config": map[string]interface{}{
+					"routes": []interface{}{
+						map[string]interface{}{"path": "/a"},
+						map[string]interface{}{"path": "/b"},
+					},
+					"timeout": 10,
+				},
+			},
+			pattern: []string{"config", "routes"},
+			op: func(tr *Tree) {
+				tr.Add([]string{"config", "retries"}, 3)
+				tr.Del([]string{"config", "timeout"})
+			},
+		},
+		{
+			name: "unchanged",
+			in: map[string]interface{}{
+				"config": map[string]interface{}{
+					"routes": []interface{}{
+						map[string]interface{}{"path": "/a"},
+						map[string]interface{}{"path": "/b"},
+					},
+					"timeout": 10,
+				},
+			},
+			pattern: []string{"config", "timeout"},
+			op:      func(tr *Tree) { tr.Add([]string{"config", "timeout"}, 10) },
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr, _ := New(tc.in)
+			var changes []Change
+			tr.Subscribe(tc.pattern, func(c Change) { changes = append(changes, c) })
+
+			tc.op(tr)
+
+			if !reflect.DeepEqual(changes, tc.changes) {
+				t.Errorf("unexpected changes: %v", changes)
+			}
+		})
+	}
+}
+
+func TestTree_SubscribeBatch(t *testing.T) {
+	tr, _ := New(map[string]interface{}{
+		"config": map[string]interface{}{"timeout": 10},
+	})
+	var batches [][]Change
+	tr.SubscribeBatch([]string{"config", "timeout"}, func(cs []Change) { batches = append(batches, cs) })
+
+	tr.Flush()
+	tr.Add([]string{"config", "timeout"}, 20)
+	tr.Add([]string{"config", "timeout"}, 30)
+	if len(batches) != 0 {
+		t.Errorf("unexpected delivery: %v", batches)
+	}
+
+	tr.Flush()
+	expected := [][]Change{
+		{
+			{Path: []string{"config", "timeout"}, Old: 10, New: 20},
+			{Path: []string{"config", "timeout"}, Old: 20, New: 30},
+		},
+	}
+	if !reflect.DeepEqual(batches, expected) {
+		t.Errorf("unexpected batches: %v", batches)
+	}
+
+	tr.Flush()
+	if len(batches) != 1 {
+		t.Errorf("unexpected batches: %v", batches)
+	}
+}
+
+func TestSubscription_Cancel(t *testing.T) {
+	tr, _ := New(map[string]interface{}{
+		"config": map[string]interface{}{"timeout": 10},
+	})
+	var changes, batched int
+	s := tr.Subscribe([]string{"config", "timeout"}, func(Change) { changes++ })
+	b := tr.SubscribeBatch([]string{"config", "timeout"}, func(cs []Change) { batched += len(cs) })
+
+	tr.Add([]string{"config", "timeout"}, 20)
+	s.Cancel()
+	b.Cancel()
+	tr.Add([]string{"config", "timeout"}, 30)
+	tr.Flush()
+
+	if changes != 1 || batched != 0 {
+		t.Errorf("unexpected deliveries: %d %d", changes, batched)
+	}
+	if len(tr.subscriptions) != 0 {
+		t.Errorf("unexpected subscriptions: %v", tr.subscriptions)
+	}
+}
+
+func TestTx_observed(t *testing.T) {
+	tr, _ := New(map[string]interface{}{
+		"config": map[string]interface{}{"timeout": 10},
+	})
+	var changes []Change
+	tr.Subscribe([]string{"config", "timeout"}, func(c Change) { changes = append(changes, c) })
+
+	tx := tr.Begin()
+	tx.Del([]string{"config", "timeout"})
+	if err := tx.Rollback(); err != nil {
+		t.Error(err)
+	}
+
+	expected := []Change{
+		{Path: []string{"config", "timeout"}, Old: 10},
+		{Path: []string{"config", "timeout"}, New: 10},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("unexpected changes: %v", changes)
+	}
+}
+
+func TestTree_Walk_observed(t *testing.T) {
+	tr, _ := New(map[string]interface{}{
+		"a": []interface{}{1, 2},
+		"b": map[string]interface{}{"c": true},
+	})
+	var changes []Change
+	tr.Subscribe([]string{"a", "*"}, func(c Change) { changes = append(changes, c) })
+
+	o := &observer{t: tr}
+	tr.root.walk(nil, func([]string, interface{}, bool) WalkAction { return Continue }, false, o)
+	if len(o.affected) != 0 {
+		t.Errorf("unexpected subscriptions: %v", o.affected)
+	}
+
+	tr.Walk(func(_ []string, v interface{}, _ bool) WalkAction {
+		if v == 2 {
+			return Replace(3)
+		}
+		return Continue
+	})
+
+	expected := []Change{{Path: []string{"a", "1"}, Old: 2, New: 3}}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("unexpected changes: %v", changes)
+	}
+}
@@ -0,0 +1,286 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"io"
+	"iter"
+	"strconv"
+
+	"github.com/starvn/flatex/internal/span"
+)
+
+// Persistent is an immutable Tree. Its operations leave the receiver
+// untouched and return a new Persistent sharing every subtree they do not
+// change, so many variants of a document can be derived cheaply and used
+// from several goroutines.
+type Persistent struct {
+	t *Tree
+}
+
+func NewPersistent(v interface{}, opts ...Option) (*Persistent, error) {
+	t, err := New(v, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Persistent{t: t}, nil
+}
+
+func (p *Persistent) Get(ks []string) interface{} {
+	return p.t.Get(ks)
+}
+
+func (p *Persistent) Lookup(ks []string) (interface{}, bool) {
+	return p.t.Lookup(ks)
+}
+
+func (p *Persistent) GetString(ks []string) (string, error) {
+	return p.t.GetString(ks)
+}
+
+func (p *Persistent) GetInt64(ks []string) (int64, error) {
+	return p.t.GetInt64(ks)
+}
+
+func (p *Persistent) GetFloat(ks []string) (float64, error) {
+	return p.t.GetFloat(ks)
+}
+
+func (p *Persistent) GetBool(ks []string) (bool, error) {
+	return p.t.GetBool(ks)
+}
+
+func (p *Persistent) GetSlice(ks []string) ([]interface{}, error) {
+	return p.t.GetSlice(ks)
+}
+
+func (p *Persistent) GetMap(ks []string) (map[string]interface{}, error) {
+	return p.t.GetMap(ks)
+}
+
+func (p *Persistent) Find(ks []string) []interface{} {
+	return p.t.Find(ks)
+}
+
+func (p *Persistent) FindPaths(ks []string) []Match {
+	return p.t.FindPaths(ks)
+}
+
+func (p *Persistent) Leaves() iter.Seq2[[]string, interface{}] {
+	return p.t.Leaves()
+}
+
+func (p *Persistent) Paths(ks []string) iter.Seq2[[]string, interface{}] {
+	return p.t.Paths(ks)
+}
+
+func (p *Persistent) Fprint(w io.Writer, opts PrintOptions) error {
+	return p.t.Fprint(w, opts)
+}
+
+func (p *Persistent) String() string {
+	return p.t.String()
+}
+
+func (p *Persistent) WriteDOT(w io.Writer) error {
+	return p.t.WriteDOT(w)
+}
+
+func (p *Persistent) WriteMermaid(w io.Writer) error {
+	return p.t.WriteMermaid(w)
+}
+
+func (p *Persistent) Add(ks []string, v interface{}) *Persistent {
+	if v == nil {
+		return p
+	}
+	return p.derive(func(c copier, t *Tree) {
+		c.add(t, ks, v)
+		t.Add(ks, v)
+	})
+}
+
+func (p *Persistent) Del(ks []string) *Persistent {
+	if len(ks) == 0 {
+		return p
+	}
+	return p.derive(func(c copier, t *Tree) {
+		c.del(t, ks)
+		t.Del(ks)
+	})
+}
+
+func (p *Persistent) Move(src, dst []string) *Persistent {
+	if len(src) == 0 || len(dst) == 0 {
+		return p
+	}
+	return p.derive(func(c copier, t *Tree) {
+		c.move(t, src, dst)
+		t.Move(src, dst)
+	})
+}
+
+func (p *Persistent) Append(src, dst []string) *Persistent {
+	if len(src) == 0 {
+		return p
+	}
+	return p.derive(func(c copier, t *Tree) {
+		c.append(t, src, dst)
+		t.Append(src, dst)
+	})
+}
+
+// derive applies fn to a copy of the tree whose root is the only node copied
+// up front. fn must copy every other node before changing it.
+func (p *Persistent) derive(fn func(c copier, t *Tree)) *Persistent {
+	c := copier{}
+	t := *p.t
+	t.root = c.fresh(p.t.root)
+	fn(c, &t)
+	return &Persistent{t: &t}
+}
+
+// copier copies the nodes an operation is about to change, remembering the
+// copies so they are not copied again
+type copier map[*node]bool
+
+func (c copier) fresh(n *node) *node {
+	if c[n] {
+		return n
+	}
+	res := n.clone()
+	c[res] = true
+	return res
+}
+
+// add, del, move and append copy the nodes t.Add, t.Del, t.Move and t.Append
+// are about to change
+func (c copier) add(t *Tree, ks []string, v interface{}) {
+	for _, n := range c.path(t.root, ks) {
+		c.value(n, v)
+	}
+}
+
+func (c copier) del(t *Tree, ks []string) {
+	if len(ks) == 0 {
+		return
+	}
+	c.path(t.root, ks[:len(ks)-1])
+}
+
+func (c copier) move(t *Tree, src, dst []string) {
+	if len(src) == 0 || len(dst) == 0 {
+		return
+	}
+	c.path(t.root, src[:len(src)-1])
+	c.path(t.root, dst[:len(dst)-1])
+}
+
+func (c copier) append(t *Tree, src, dst []string) {
+	if len(src) == 0 {
+		return
+	}
+	elements1, _ := t.Get(src).([]interface{})
+	elements2, _ := t.Get(dst).([]interface{})
+	c.path(t.root, src[:len(src)-1])
+	for _, n := range c.path(t.root, dst) {
+		c.value(n, append(elements2, elements1...))
+	}
+}
+
+// path copies the nodes matching every prefix of the pattern ks under n,
+// which must be a copy already, and returns the nodes matching ks
+func (c copier) path(n *node, ks []string) []*node {
+	next := []*node{n}
+	for _, k := range ks {
+		var acc []*node
+		for _, n := range next {
+			for _, e := range n.matching(k) {
+				e.n = c.fresh(e.n)
+				acc = append(acc, e.n)
+			}
+		}
+		next = acc
+	}
+	return next
+}
+
+// all copies every node under n, which must be a copy already
+func (c copier) all(n *node) {
+	for _, e := range n.edges {
+		e.n = c.fresh(e.n)
+		c.all(e.n)
+	}
+}
+
+// value copies the descendants of n that flattening v into it would change
+func (c copier) value(n *node, v interface{}) {
+	var children map[string]interface{}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		children = v
+	case []interface{}:
+		children = make(map[string]interface{}, len(v))
+		for i, x := range v {
+			children[strconv.Itoa(i)] = x
+		}
+	default:
+		return
+	}
+
+	for _, e := range n.edges {
+		if x, ok := children[e.label]; ok {
+			e.n = c.fresh(e.n)
+			c.value(e.n, x)
+		}
+	}
+}
+
+// clone copies the node and its edges, sharing the children
+func (n *node) clone() *node {
+	res := &node{
+		Value:        n.Value,
+		isCollection: n.isCollection,
+		edges:        make([]*edge, len(n.edges)),
+	}
+	for i, e := range n.edges {
+		res.edges[i] = &edge{label: e.label, n: e.n}
+	}
+	if n.index != nil {
+		res.index = make(map[string]*edge, len(n.index))
+		for _, e := range res.edges {
+			res.index[e.label] = e
+		}
+	}
+	return res
+}
+
+// matching returns the edges matched by the path segment k
+func (n *node) matching(k string) []*edge {
+	if k == wildcard {
+		return n.edges
+	}
+	if n.isCollection {
+		if s, ok := span.Parse(k, len(n.edges)); ok {
+			return n.edges[s.Lo:s.Hi]
+		}
+	}
+	if e := n.child(k); e != nil {
+		return []*edge{e}
+	}
+	return nil
+}
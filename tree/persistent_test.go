@@ -0,0 +1,212 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestPersistent(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		in      map[string]interface{}
+		mutable func(*Tree)
+		derive  func(*Persistent) *Persistent
+	}{
+		{
+			name: "add",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"b": 1, "c": map[string]interface{}{"d": true}},
+					map[string]interface{}{"b": 2, "c": map[string]interface{}{"d": false}},
+				},
+				"e": map[string]interface{}{"f": "g", "h": []interface{}{1, 2}},
+				"i": 42,
+			},
+			mutable: func(tr *Tree) { tr.Add([]string{"a", "0", "c", "x"}, 1) },
+			derive:  func(p *Persistent) *Persistent { return p.Add([]string{"a", "0", "c", "x"}, 1) },
+		},
+		{
+			name: "add_merging",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"b": 1, "c": map[string]interface{}{"d": true}},
+					map[string]interface{}{"b": 2, "c": map[string]interface{}{"d": false}},
+				},
+				"e": map[string]interface{}{"f": "g", "h": []interface{}{1, 2}},
+				"i": 42,
+			},
+			mutable: func(tr *Tree) { tr.Add([]string{"e"}, map[string]interface{}{"h": []interface{}{3}}) },
+			derive: func(p *Persistent) *Persistent {
+				return p.Add([]string{"e"}, map[string]interface{}{"h": []interface{}{3}})
+			},
+		},
+		{
+			name: "del",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"b": 1, "c": map[string]interface{}{"d": true}},
+					map[string]interface{}{"b": 2, "c": map[string]interface{}{"d": false}},
+				},
+				"e": map[string]interface{}{"f": "g", "h": []interface{}{1, 2}},
+				"i": 42,
+			},
+			mutable: func(tr *Tree) { tr.Del([]string{"a", "-1"}) },
+			derive:  func(p *Persistent) *Persistent { return p.Del([]string{"a", "-1"}) },
+		},
+		{
+			name: "del_wildcard",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"b": 1, "c": map[string]interface{}{"d": true}},
+					map[string]interface{}{"b": 2, "c": map[string]interface{}{"d": false}},
+				},
+				"e": map[string]interface{}{"f": "g", "h": []interface{}{1, 2}},
+				"i": 42,
+			},
+			mutable: func(tr *Tree) { tr.Del([]string{"a", "*", "c", "d"}) },
+			derive:  func(p *Persistent) *Persistent { return p.Del([]string{"a", "*", "c", "d"}) },
+		},
+		{
+			name: "move",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"b": 1, "c": map[string]interface{}{"d": true}},
+					map[string]interface{}{"b": 2, "c": map[string]interface{}{"d": false}},
+				},
+				"e": map[string]interface{}{"f": "g", "h": []interface{}{1, 2}},
+				"i": 42,
+			},
+			mutable: func(tr *Tree) { tr.Move([]string{"a", "*", "c"}, []string{"a", "*", "x"}) },
+			derive:  func(p *Persistent) *Persistent { return p.Move([]string{"a", "*", "c"}, []string{"a", "*", "x"}) },
+		},
+		{
+			name: "move_into_collection",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"b": 1, "c": map[string]interface{}{"d": true}},
+					map[string]interface{}{"b": 2, "c": map[string]interface{}{"d": false}},
+				},
+				"e": map[string]interface{}{"f": "g", "h": []interface{}{1, 2}},
+				"i": 42,
+			},
+			mutable: func(tr *Tree) { tr.Move([]string{"e", "f"}, []string{"e", "h", "[]"}) },
+			derive:  func(p *Persistent) *Persistent { return p.Move([]string{"e", "f"}, []string{"e", "h", "[]"}) },
+		},
+		{
+			name: "move_to_new_parent",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"b": 1, "c": map[string]interface{}{"d": true}},
+					map[string]interface{}{"b": 2, "c": map[string]interface{}{"d": false}},
+				},
+				"e": map[string]interface{}{"f": "g", "h": []interface{}{1, 2}},
+				"i": 42,
+			},
+			mutable: func(tr *Tree) { tr.Move([]string{"e"}, []string{"x", "y"}) },
+			derive:  func(p *Persistent) *Persistent { return p.Move([]string{"e"}, []string{"x", "y"}) },
+		},
+		{
+			name: "append",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"b": 1, "c": map[string]interface{}{"d": true}},
+					map[string]interface{}{"b": 2, "c": map[string]interface{}{"d": false}},
+				},
+				"e": map[string]interface{}{"f": "g", "h": []interface{}{1, 2}},
+				"i": 42,
+			},
+			mutable: func(tr *Tree) { tr.Append([]string{"e", "h"}, []string{"a"}) },
+			derive:  func(p *Persistent) *Persistent { return p.Append([]string{"e", "h"}, []string{"a"}) },
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr, _ := New(tc.in)
+			tc.mutable(tr)
+
+			p, _ := NewPersistent(tc.in)
+			res := tc.derive(p)
+
+			if v := res.Get([]string{}); !reflect.DeepEqual(v, tr.Get([]string{})) {
+				t.Errorf("unexpected result: %v", v)
+			}
+			if v := p.Get([]string{}); !reflect.DeepEqual(v, tc.in) {
+				t.Errorf("the original tree has changed: %v", v)
+			}
+		})
+	}
+}
+
+func TestPersistent_sharing(t *testing.T) {
+	p, _ := NewPersistent(map[string]interface{}{
+		"a": []interface{}{
+			map[string]interface{}{"b": 1, "c": map[string]interface{}{"d": true}},
+			map[string]interface{}{"b": 2, "c": map[string]interface{}{"d": false}},
+		},
+		"e": map[string]interface{}{"f": "g"},
+	})
+	res := p.Del([]string{"a", "0", "b"})
+
+	if p.t.root == res.t.root {
+		t.Error("the root has not been copied")
+	}
+	if p.t.root.find("e") != res.t.root.find("e") {
+		t.Error("the untouched subtree has been copied")
+	}
+	if p.t.root.find("a", "1") != res.t.root.find("a", "1") {
+		t.Error("the untouched element has been copied")
+	}
+	if p.t.root.find("a", "0") == res.t.root.find("a", "0") {
+		t.Error("the changed element has not been copied")
+	}
+}
+
+func TestPersistent_concurrent(t *testing.T) {
+	in := map[string]interface{}{
+		"a": []interface{}{
+			map[string]interface{}{"b": 1},
+			map[string]interface{}{"b": 2},
+		},
+		"e": map[string]interface{}{"f": "g"},
+		"i": 42,
+	}
+	p, _ := NewPersistent(in)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			k := fmt.Sprintf("k%d", i)
+			res := p.Add([]string{"e", k}, i).Move([]string{"a", "*", "b"}, []string{"a", "*", k}).Del([]string{"i"})
+			if v := res.Get([]string{"e", k}); v != i {
+				t.Errorf("unexpected value: %v", v)
+			}
+			if v := res.Get([]string{"a", "*", k}); !reflect.DeepEqual(v, []interface{}{1, 2}) {
+				t.Errorf("unexpected values: %v", v)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if v := p.Get([]string{}); !reflect.DeepEqual(v, in) {
+		t.Errorf("the original tree has changed: %v", v)
+	}
+}
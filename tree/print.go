@@ -0,0 +1,164 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	colorReset  = "\x1b[0m"
+	colorLabel  = "\x1b[34m"
+	colorString = "\x1b[32m"
+	colorNumber = "\x1b[36m"
+	colorBool   = "\x1b[33m"
+	colorNil    = "\x1b[90m"
+)
+
+// PrintOptions customizes the rendering of Fprint
+type PrintOptions struct {
+	// MaxDepth is the number of levels to render. The nodes in the last
+	// level with children are marked with an ellipsis. Zero means no limit.
+	MaxDepth int
+	// MaxValueLength truncates the rendered values to the given number of
+	// runes. Zero means no limit.
+	MaxValueLength int
+	// SummarizeCollections renders the number of elements of the collections
+	// instead of their content
+	SummarizeCollections bool
+	// SortKeys renders the keys of the objects in lexicographic order without
+	// sorting the tree itself
+	SortKeys bool
+	// Color highlights the labels and the values with ANSI escape codes
+	Color bool
+}
+
+// Fprint renders the tree into w as a box-drawing diagram, one line per node
+func (t *Tree) Fprint(w io.Writer, opts PrintOptions) error {
+	p := printer{w: bufio.NewWriter(w), opts: opts}
+	p.edges(t.root, "", 1)
+	return p.w.Flush()
+}
+
+// String renders the tree with the default options
+func (t *Tree) String() string {
+	sb := new(strings.Builder)
+	t.Fprint(sb, PrintOptions{})
+	return sb.String()
+}
+
+type printer struct {
+	w    *bufio.Writer
+	opts PrintOptions
+}
+
+func (p printer) edges(n *node, prefix string, depth int) {
+	edges := n.edges
+	if p.opts.SortKeys && !n.isCollection {
+		edges = make([]*edge, len(n.edges))
+		copy(edges, n.edges)
+		sort.SliceStable(edges, func(i, j int) bool { return edges[i].label < edges[j].label })
+	}
+
+	for i, e := range edges {
+		isLast := i == len(edges)-1
+		p.w.WriteString(prefix)
+		if isLast {
+			p.w.WriteString("└── ")
+		} else {
+			p.w.WriteString("├── ")
+		}
+		p.colored(colorLabel, e.label)
+
+		if e.n.IsLeaf() {
+			p.w.WriteByte('\t')
+			p.value(e.n.Value)
+			p.w.WriteByte('\n')
+			continue
+		}
+
+		if e.n.isCollection {
+			if p.opts.SummarizeCollections {
+				p.w.WriteString(summary(len(e.n.edges)))
+				p.w.WriteByte('\n')
+				continue
+			}
+			p.w.WriteString(" []")
+		}
+
+		if p.opts.MaxDepth > 0 && depth >= p.opts.MaxDepth {
+			p.w.WriteString(" …\n")
+			continue
+		}
+		p.w.WriteByte('\n')
+
+		if isLast {
+			p.edges(e.n, prefix+"    ", depth+1)
+		} else {
+			p.edges(e.n, prefix+"│   ", depth+1)
+		}
+	}
+}
+
+func (p printer) value(v interface{}) {
+	s := fmt.Sprintf("%+v", v)
+	if max := p.opts.MaxValueLength; max > 0 && utf8.RuneCountInString(s) > max {
+		s = string([]rune(s)[:max]) + "…"
+	}
+
+	if !p.opts.Color {
+		p.w.WriteString(s)
+		return
+	}
+
+	switch v.(type) {
+	case nil:
+		p.colored(colorNil, s)
+	case string:
+		p.colored(colorString, s)
+	case bool:
+		p.colored(colorBool, s)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+		p.colored(colorNumber, s)
+	default:
+		p.w.WriteString(s)
+	}
+}
+
+func (p printer) colored(color, s string) {
+	if !p.opts.Color {
+		p.w.WriteString(s)
+		return
+	}
+	p.w.WriteString(color)
+	p.w.WriteString(s)
+	p.w.WriteString(colorReset)
+}
+
+func summary(n int) string {
+	if n == 1 {
+		return " [1 item]"
+	}
+	return " [" + strconv.Itoa(n) + " items]"
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTree_Fprint(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts PrintOptions
+		out  string
+	}{
+		{
+			name: "default",
+			out: `
+├── b
+│   ├── z	a long value
+│   └── c []
+│       ├── 0	1
+│       └── 1
+│           └── d	true
+└── a	<nil>
+`,
+		},
+		{
+			name: "max_depth",
+			opts: PrintOptions{MaxDepth: 2},
+			out: `
+├── b
+│   ├── z	a long value
+│   └── c [] …
+└── a	<nil>
+`,
+		},
+		{
+			name: "truncated_values",
+			opts: PrintOptions{MaxValueLength: 6},
+			out: `
+├── b
+│   ├── z	a long…
+│   └── c []
+│       ├── 0	1
+│       └── 1
+│           └── d	true
+└── a	<nil>
+`,
+		},
+		{
+			name: "summarized_collections",
+			opts: PrintOptions{SummarizeCollections: true},
+			out: `
+├── b
+│   ├── z	a long value
+│   └── c [2 items]
+└── a	<nil>
+`,
+		},
+		{
+			name: "sorted_keys",
+			opts: PrintOptions{SortKeys: true},
+			out: `
+├── a	<nil>
+└── b
+    ├── c []
+    │   ├── 0	1
+    │   └── 1
+    │       └── d	true
+    └── z	a long value
+`,
+		},
+		{
+			name: "colors",
+			opts: PrintOptions{Color: true, MaxDepth: 2},
+			out: "\n" +
+				"├── \x1b[34mb\x1b[0m\n" +
+				"│   ├── \x1b[34mz\x1b[0m\t\x1b[32ma long value\x1b[0m\n" +
+				"│   └── \x1b[34mc\x1b[0m [] …\n" +
+				"└── \x1b[34ma\x1b[0m\t\x1b[90m<nil>\x1b[0m\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr, _ := New(map[string]interface{}{})
+			tr.Add([]string{"b", "z"}, "a long value")
+			tr.Add([]string{"b", "c"}, []interface{}{1, map[string]interface{}{"d": true}})
+			tr.root.Add([]string{"a"}, nil)
+			before := tr.String()
+
+			sb := new(strings.Builder)
+			if err := tr.Fprint(sb, tc.opts); err != nil {
+				t.Error(err)
+				return
+			}
+			if out := "\n" + sb.String(); out != tc.out {
+				t.Errorf("unexpected result:\nhave:%s\nwant:%s", out, tc.out)
+			}
+			if after := tr.String(); after != before {
+				t.Errorf("the tree has been modified:%s", after)
+			}
+		})
+	}
+}
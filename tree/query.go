@@ -0,0 +1,44 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+// Match is a value found by FindPaths along with its concrete path
+type Match struct {
+	Path  []string
+	Value interface{}
+}
+
+// Find returns the values matching the pattern. Unlike Get, the missing
+// matches are skipped and the results of nested wildcards are collected into
+// a single list instead of nested slices.
+func (t *Tree) Find(ks []string) []interface{} {
+	res := []interface{}{}
+	for _, v := range t.Paths(ks) {
+		res = append(res, v)
+	}
+	return res
+}
+
+// FindPaths behaves like Find but returns the concrete path of every value
+// too
+func (t *Tree) FindPaths(ks []string) []Match {
+	res := []Match{}
+	for p, v := range t.Paths(ks) {
+		res = append(res, Match{Path: p, Value: v})
+	}
+	return res
+}
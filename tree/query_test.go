@@ -0,0 +1,84 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTree_Find(t *testing.T) {
+	tr, _ := New(map[string]interface{}{
+		"a": []interface{}{
+			map[string]interface{}{"b": []interface{}{map[string]interface{}{"c": 1}}},
+			map[string]interface{}{"x": 2},
+			map[string]interface{}{"b": []interface{}{map[string]interface{}{"c": 3}, map[string]interface{}{"d": 4}}},
+		},
+		"f": "g",
+	})
+
+	for _, tc := range []struct {
+		pattern string
+		get     interface{}
+		find    []interface{}
+	}{
+		{
+			pattern: "a.*.b.*.c",
+			get:     []interface{}{[]interface{}{1}, nil, []interface{}{3, nil}},
+			find:    []interface{}{1, 3},
+		},
+		{
+			pattern: "a.*.x",
+			get:     []interface{}{nil, 2, nil},
+			find:    []interface{}{2},
+		},
+		{
+			pattern: "f.*",
+			get:     nil,
+			find:    []interface{}{},
+		},
+	} {
+		ks := strings.Split(tc.pattern, ".")
+		if v := tr.Get(ks); !reflect.DeepEqual(v, tc.get) {
+			t.Errorf("unexpected Get result (%s): %#v", tc.pattern, v)
+		}
+		if v := tr.Find(ks); !reflect.DeepEqual(v, tc.find) {
+			t.Errorf("unexpected Find result (%s): %#v", tc.pattern, v)
+		}
+	}
+}
+
+func TestTree_FindPaths(t *testing.T) {
+	tr, _ := New(map[string]interface{}{
+		"a": []interface{}{
+			map[string]interface{}{"b": []interface{}{map[string]interface{}{"c": 1}}},
+			map[string]interface{}{"x": 2},
+			map[string]interface{}{"b": []interface{}{map[string]interface{}{"c": 3}, map[string]interface{}{"d": 4}}},
+		},
+		"f": "g",
+	})
+
+	res := tr.FindPaths([]string{"a", "*", "b", "*", "c"})
+	expected := []Match{
+		{Path: []string{"a", "0", "b", "0", "c"}, Value: 1},
+		{Path: []string{"a", "2", "b", "0", "c"}, Value: 3},
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("unexpected result: %v", res)
+	}
+}
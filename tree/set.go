@@ -0,0 +1,135 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import "github.com/starvn/flatex/internal/span"
+
+// Set writes v into every path matching the pattern, replacing their current
+// content. Wildcards and slice segments fan out over the existing children,
+// while the missing concrete segments are created unless their parent holds
+// a scalar value. Null values are ignored, as Add does.
+func (t *Tree) Set(ks []string, v interface{}) {
+	if v == nil {
+		return
+	}
+	if t.tx != nil {
+		t.tx.c.path(t.root, ks)
+	}
+	t.observe(func() {
+		t.root.update(ks, true, func(*node) interface{} { return v })
+	}, ks)
+}
+
+// Replace behaves like Set but only writes into the paths already in the
+// tree
+func (t *Tree) Replace(ks []string, v interface{}) {
+	if v == nil {
+		return
+	}
+	if t.tx != nil {
+		t.tx.c.path(t.root, ks)
+	}
+	t.observe(func() {
+		t.root.update(ks, false, func(*node) interface{} { return v })
+	}, ks)
+}
+
+// SetFunc writes into every path Set would write the value returned by fn,
+// which receives the current value of the path or nil if it is missing. The
+// paths fn returns nil for are left untouched.
+func (t *Tree) SetFunc(ks []string, fn func(old interface{}) interface{}) {
+	if t.tx != nil {
+		t.tx.c.path(t.root, ks)
+	}
+	t.observe(func() {
+		t.root.update(ks, true, func(n *node) interface{} {
+			if n == nil {
+				return fn(nil)
+			}
+			return fn(n.expand())
+		})
+	}, ks)
+}
+
+// update replaces the content of the nodes matching the pattern with the
+// value returned by fn, which receives nil for the nodes to create. The nodes
+// fn returns nil for are neither changed nor created.
+func (n *node) update(ks []string, create bool, fn func(*node) interface{}) {
+	if len(ks) == 0 {
+		if v := fn(n); v != nil {
+			n.set(v)
+		}
+		return
+	}
+
+	if ks[0] == wildcard {
+		for _, e := range n.edges {
+			e.n.update(ks[1:], create, fn)
+		}
+		return
+	}
+
+	if e := n.child(ks[0]); e != nil {
+		e.n.update(ks[1:], create, fn)
+		return
+	}
+
+	if n.isCollection {
+		if s, ok := span.Parse(ks[0], len(n.edges)); ok {
+			for _, e := range n.edges[s.Lo:s.Hi] {
+				e.n.update(ks[1:], create, fn)
+			}
+			return
+		}
+	}
+
+	if !create || isPattern(ks) || n.isScalar() {
+		return
+	}
+
+	v := fn(nil)
+	if v == nil {
+		return
+	}
+	parent := n.ensure(ks)
+	child := newNode()
+	parent.addEdge(ks[len(ks)-1], &edge{n: child})
+	child.set(v)
+}
+
+// isScalar reports if the node holds a value other than null or an empty
+// collection or object
+func (n *node) isScalar() bool {
+	if !n.IsLeaf() || n.isCollection || n.Value == nil {
+		return false
+	}
+	_, ok := n.Value.(map[string]interface{})
+	return !ok
+}
+
+// isPattern reports if any segment can match more than one node
+func isPattern(ks []string) bool {
+	for _, k := range ks {
+		if k == wildcard {
+			return true
+		}
+		if _, ok := span.Parse(k, 0); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTree_Set(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		op      func(*Tree, []string)
+		pattern string
+		out     string
+	}{
+		{
+			name:    "fan_out",
+			op:      func(tr *Tree, ks []string) { tr.Set(ks, "EUR") },
+			pattern: "items.*.currency",
+			out: `
+├── items []
+│   ├── 0
+│   │   ├── currency	EUR
+│   │   └── price	10
+│   └── 1
+│       ├── currency	EUR
+│       └── price	20
+└── meta
+    └── total	30
+`,
+		},
+		{
+			name:    "subtree",
+			op:      func(tr *Tree, ks []string) { tr.Set(ks, map[string]interface{}{"count": 2}) },
+			pattern: "meta",
+			out: `
+├── items []
+│   ├── 0
+│   │   ├── currency	USD
+│   │   └── price	10
+│   └── 1
+│       └── price	20
+└── meta
+    └── count	2
+`,
+		},
+		{
+			name:    "missing_path",
+			op:      func(tr *Tree, ks []string) { tr.Set(ks, true) },
+			pattern: "meta.flags.0.valid",
+			out: `
+├── items []
+│   ├── 0
+│   │   ├── currency	USD
+│   │   └── price	10
+│   └── 1
+│       └── price	20
+└── meta
+    ├── flags []
+    │   └── 0
+    │       └── valid	true
+    └── total	30
+`,
+		},
+		{
+			name:    "missing_path_with_wildcard",
+			op:      func(tr *Tree, ks []string) { tr.Set(ks, true) },
+			pattern: "meta.*.valid",
+			out: `
+├── items []
+│   ├── 0
+│   │   ├── currency	USD
+│   │   └── price	10
+│   └── 1
+│       └── price	20
+└── meta
+    └── total	30
+`,
+		},
+		{
+			name:    "replace",
+			op:      func(tr *Tree, ks []string) { tr.Replace(ks, "EUR") },
+			pattern: "items.*.currency",
+			out: `
+├── items []
+│   ├── 0
+│   │   ├── currency	EUR
+│   │   └── price	10
+│   └── 1
+│       └── price	20
+└── meta
+    └── total	30
+`,
+		},
+		{
+			name:    "replace_missing",
+			op:      func(tr *Tree, ks []string) { tr.Replace(ks, 0) },
+			pattern: "meta.count",
+			out: `
+├── items []
+│   ├── 0
+│   │   ├── currency	USD
+│   │   └── price	10
+│   └── 1
+│       └── price	20
+└── meta
+    └── total	30
+`,
+		},
+		{
+			name: "func",
+			op: func(tr *Tree, ks []string) {
+				tr.SetFunc(ks, func(old interface{}) interface{} {
+					if old == nil {
+						return 0
+					}
+					return old.(int) * 2
+				})
+			},
+			pattern: "items.-2:.price",
+			out: `
+├── items []
+│   ├── 0
+│   │   ├── currency	USD
+│   │   └── price	20
+│   └── 1
+│       └── price	40
+└── meta
+    └── total	30
+`,
+		},
+		{
+			name:    "nil",
+			op:      func(tr *Tree, ks []string) { tr.Set(ks, nil) },
+			pattern: "items.*.currency",
+			out: `
+├── items []
+│   ├── 0
+│   │   ├── currency	USD
+│   │   └── price	10
+│   └── 1
+│       └── price	20
+└── meta
+    └── total	30
+`,
+		},
+		{
+			name: "func_nil",
+			op: func(tr *Tree, ks []string) {
+				tr.SetFunc(ks, func(old interface{}) interface{} {
+					if old == "USD" {
+						return "EUR"
+					}
+					return nil
+				})
+			},
+			pattern: "items.0:.currency",
+			out: `
+├── items []
+│   ├── 0
+│   │   ├── currency	EUR
+│   │   └── price	10
+│   └── 1
+│       └── price	20
+└── meta
+    └── total	30
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr, _ := New(map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"price": 10, "currency": "USD"},
+					map[string]interface{}{"price": 20},
+				},
+				"meta": map[string]interface{}{"total": 30},
+			})
+
+			tc.op(tr, strings.Split(tc.pattern, "."))
+			tr.Sort()
+
+			if out := "\n" + tr.String(); out != tc.out {
+				t.Errorf("unexpected result (%s):\nhave:%s\nwant:%s", tc.pattern, out, tc.out)
+			}
+		})
+	}
+}
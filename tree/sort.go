@@ -0,0 +1,144 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"sort"
+)
+
+// Entry is a child of a node being sorted by SortBy
+type Entry struct {
+	// Label is the key of the child in an object or its position before
+	// sorting in a collection
+	Label string
+	n     *node
+}
+
+// Get returns the value at the given path under the entry
+func (e Entry) Get(ks []string) interface{} {
+	return e.n.Get(ks...)
+}
+
+// SortBy sorts the children of every node matching the pattern with less,
+// keeping the order of the children less considers equal. The elements of
+// collections are relabeled with their new positions.
+func (t *Tree) SortBy(ks []string, less func(a, b Entry) bool) {
+	if t.tx != nil {
+		t.tx.c.path(t.root, ks)
+	}
+	t.observe(func() {
+		for _, n := range t.root.findAll(ks) {
+			n.sortBy(less)
+		}
+	}, ks)
+}
+
+// ByField returns a less function for SortBy ordering the entries by the
+// value at the given path under them. Numbers, strings and booleans are
+// compared by value, values of different kinds are ordered by kind, and the
+// entries missing the path go last in both directions.
+func ByField(ks []string, descending bool) func(a, b Entry) bool {
+	return func(a, b Entry) bool {
+		x, okX := a.n.lookup(ks...)
+		y, okY := b.n.lookup(ks...)
+		if !okX || !okY {
+			return okX
+		}
+		if descending {
+			return compareValues(y, x) < 0
+		}
+		return compareValues(x, y) < 0
+	}
+}
+
+func (n *node) sortBy(less func(a, b Entry) bool) {
+	sort.SliceStable(n.edges, func(i, j int) bool {
+		return less(Entry{Label: n.edges[i].label, n: n.edges[i].n}, Entry{Label: n.edges[j].label, n: n.edges[j].n})
+	})
+	if n.isCollection {
+		n.reindex()
+	}
+}
+
+// findAll returns the nodes matching the pattern
+func (n *node) findAll(ks []string) []*node {
+	next := []*node{n}
+	for _, k := range ks {
+		var acc []*node
+		for _, n := range next {
+			for _, e := range n.matching(k) {
+				acc = append(acc, e.n)
+			}
+		}
+		next = acc
+	}
+	return next
+}
+
+// compareValues orders null before booleans, numbers, strings and the rest
+// of values, which are considered equal
+func compareValues(a, b interface{}) int {
+	if ra, rb := valueRank(a), valueRank(b); ra != rb {
+		return ra - rb
+	}
+
+	switch x := a.(type) {
+	case bool:
+		y := b.(bool)
+		if x == y {
+			return 0
+		}
+		if !x {
+			return -1
+		}
+		return 1
+	case string:
+		y := b.(string)
+		if x < y {
+			return -1
+		}
+		if x > y {
+			return 1
+		}
+		return 0
+	}
+
+	x, okX := toFloat64(a)
+	y, okY := toFloat64(b)
+	if !okX || !okY || x == y {
+		return 0
+	}
+	if x < y {
+		return -1
+	}
+	return 1
+}
+
+func valueRank(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case string:
+		return 3
+	}
+	if _, ok := toFloat64(v); ok {
+		return 2
+	}
+	return 4
+}
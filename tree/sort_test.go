@@ -0,0 +1,191 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTree_SortBy(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		in      map[string]interface{}
+		pattern []string
+		less    func(a, b Entry) bool
+		path    []string
+		out     interface{}
+	}{
+		{
+			name: "by_field",
+			in: map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"id": "a", "price": 10},
+					map[string]interface{}{"id": "b", "price": 30.5},
+					map[string]interface{}{"id": "c"},
+					map[string]interface{}{"id": "d", "price": 10},
+					map[string]interface{}{"id": "e", "price": 20},
+				},
+				"orders": []interface{}{
+					map[string]interface{}{"items": []interface{}{3, 1, 2}},
+					map[string]interface{}{"items": []interface{}{"b", "a"}},
+				},
+			},
+			pattern: []string{"items"},
+			less:    ByField([]string{"price"}, false),
+			path:    []string{"items", "*", "id"},
+			out:     []interface{}{"a", "d", "e", "b", "c"},
+		},
+		{
+			name: "by_field_descending",
+			in: map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"id": "a", "price": 10},
+					map[string]interface{}{"id": "b", "price": 30.5},
+					map[string]interface{}{"id": "c"},
+					map[string]interface{}{"id": "d", "price": 10},
+					map[string]interface{}{"id": "e", "price": 20},
+				},
+				"orders": []interface{}{
+					map[string]interface{}{"items": []interface{}{3, 1, 2}},
+					map[string]interface{}{"items": []interface{}{"b", "a"}},
+				},
+			},
+			pattern: []string{"items"},
+			less:    ByField([]string{"price"}, true),
+			path:    []string{"items", "*", "id"},
+			out:     []interface{}{"b", "e", "a", "d", "c"},
+		},
+		{
+			name: "by_value",
+			in: map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"id": "a", "price": 10},
+					map[string]interface{}{"id": "b", "price": 30.5},
+					map[string]interface{}{"id": "c"},
+					map[string]interface{}{"id": "d", "price": 10},
+					map[string]interface{}{"id": "e", "price": 20},
+				},
+				"orders": []interface{}{
+					map[string]interface{}{"items": []interface{}{3, 1, 2}},
+					map[string]interface{}{"items": []interface{}{"b", "a"}},
+				},
+			},
+			pattern: []string{"orders", "*", "items"},
+			less:    ByField([]string{}, false),
+			path:    []string{"orders", "*", "items"},
+			out:     []interface{}{[]interface{}{1, 2, 3}, []interface{}{"a", "b"}},
+		},
+		{
+			name: "by_position",
+			in: map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"id": "a", "price": 10},
+					map[string]interface{}{"id": "b", "price": 30.5},
+					map[string]interface{}{"id": "c"},
+					map[string]interface{}{"id": "d", "price": 10},
+					map[string]interface{}{"id": "e", "price": 20},
+				},
+				"orders": []interface{}{
+					map[string]interface{}{"items": []interface{}{3, 1, 2}},
+					map[string]interface{}{"items": []interface{}{"b", "a"}},
+				},
+			},
+			pattern: []string{"items"},
+			less:    func(a, b Entry) bool { return a.Label > b.Label },
+			path:    []string{"items", "*", "id"},
+			out:     []interface{}{"e", "d", "c", "b", "a"},
+		},
+		{
+			name: "missing",
+			in: map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"id": "a", "price": 10},
+					map[string]interface{}{"id": "b", "price": 30.5},
+					map[string]interface{}{"id": "c"},
+					map[string]interface{}{"id": "d", "price": 10},
+					map[string]interface{}{"id": "e", "price": 20},
+				},
+				"orders": []interface{}{
+					map[string]interface{}{"items": []interface{}{3, 1, 2}},
+					map[string]interface{}{"items": []interface{}{"b", "a"}},
+				},
+			},
+			pattern: []string{"unknown"},
+			less:    ByField([]string{}, false),
+			path:    []string{"items", "*", "id"},
+			out:     []interface{}{"a", "b", "c", "d", "e"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr, _ := New(tc.in)
+			tr.SortBy(tc.pattern, tc.less)
+
+			if v := tr.Get(tc.path); !reflect.DeepEqual(v, tc.out) {
+				t.Errorf("unexpected result: %v", v)
+			}
+			if _, err := tr.GetString([]string{"items", "4", "id"}); err != nil {
+				t.Errorf("the elements have not been relabeled: %v", err)
+			}
+		})
+	}
+}
+
+func TestTree_SortBy_keys(t *testing.T) {
+	tr, _ := New(map[string]interface{}{"b": 1, "a": 3, "c": 2})
+	tr.SortBy([]string{}, func(a, b Entry) bool { return a.Label > b.Label })
+
+	var keys []string
+	for p := range tr.Leaves() {
+		keys = append(keys, p[0])
+	}
+	if !reflect.DeepEqual(keys, []string{"c", "b", "a"}) {
+		t.Errorf("unexpected keys: %v", keys)
+	}
+
+	tr.SortBy([]string{}, ByField([]string{}, false))
+	keys = keys[:0]
+	for p := range tr.Leaves() {
+		keys = append(keys, p[0])
+	}
+	if !reflect.DeepEqual(keys, []string{"b", "c", "a"}) {
+		t.Errorf("unexpected keys: %v", keys)
+	}
+}
+
+func Test_compareValues(t *testing.T) {
+	for _, tc := range []struct {
+		a, b interface{}
+		res  int
+	}{
+		{a: nil, b: false, res: -1},
+		{a: false, b: true, res: -1},
+		{a: true, b: 0, res: -1},
+		{a: 1, b: 1.0, res: 0},
+		{a: 2, b: 1.5, res: 1},
+		{a: 1e9, b: "a", res: -1},
+		{a: "a", b: "b", res: -1},
+		{a: "a", b: []interface{}{}, res: -1},
+	} {
+		if res := compareValues(tc.a, tc.b); res != tc.res {
+			t.Errorf("%v vs %v: unexpected result %d", tc.a, tc.b, res)
+		}
+		if res := compareValues(tc.b, tc.a); res != -tc.res {
+			t.Errorf("%v vs %v: unexpected result %d", tc.b, tc.a, res)
+		}
+	}
+}
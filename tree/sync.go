@@ -0,0 +1,225 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// SyncTree is a Tree safe for concurrent use. Every change builds a new
+// version of the tree that is swapped in atomically, so readers never wait
+// for writers and always see the result of complete operations.
+//
+// Sort, Walk and WalkPostOrder copy the whole tree, while the rest of the
+// changes only copy the nodes they touch.
+type SyncTree struct {
+	// mu serializes the writers
+	mu sync.Mutex
+	p  atomic.Pointer[Persistent]
+}
+
+func NewSyncTree(v interface{}, opts ...Option) (*SyncTree, error) {
+	p, err := NewPersistent(v, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s := &SyncTree{}
+	s.p.Store(p)
+	return s, nil
+}
+
+// Snapshot returns the current version of the tree, which is not affected by
+// later changes
+func (s *SyncTree) Snapshot() *Persistent {
+	return s.p.Load()
+}
+
+func (s *SyncTree) Get(ks []string) interface{} {
+	return s.Snapshot().Get(ks)
+}
+
+func (s *SyncTree) Lookup(ks []string) (interface{}, bool) {
+	return s.Snapshot().Lookup(ks)
+}
+
+func (s *SyncTree) GetString(ks []string) (string, error) {
+	return s.Snapshot().GetString(ks)
+}
+
+func (s *SyncTree) GetInt64(ks []string) (int64, error) {
+	return s.Snapshot().GetInt64(ks)
+}
+
+func (s *SyncTree) GetFloat(ks []string) (float64, error) {
+	return s.Snapshot().GetFloat(ks)
+}
+
+func (s *SyncTree) GetBool(ks []string) (bool, error) {
+	return s.Snapshot().GetBool(ks)
+}
+
+func (s *SyncTree) GetSlice(ks []string) ([]interface{}, error) {
+	return s.Snapshot().GetSlice(ks)
+}
+
+func (s *SyncTree) GetMap(ks []string) (map[string]interface{}, error) {
+	return s.Snapshot().GetMap(ks)
+}
+
+func (s *SyncTree) Find(ks []string) []interface{} {
+	return s.Snapshot().Find(ks)
+}
+
+func (s *SyncTree) FindPaths(ks []string) []Match {
+	return s.Snapshot().FindPaths(ks)
+}
+
+func (s *SyncTree) Add(ks []string, v interface{}) {
+	s.derive(func(p *Persistent) *Persistent { return p.Add(ks, v) })
+}
+
+func (s *SyncTree) Del(ks []string) {
+	s.derive(func(p *Persistent) *Persistent { return p.Del(ks) })
+}
+
+func (s *SyncTree) Move(src, dst []string) {
+	s.derive(func(p *Persistent) *Persistent { return p.Move(src, dst) })
+}
+
+func (s *SyncTree) Append(src, dst []string) {
+	s.derive(func(p *Persistent) *Persistent { return p.Append(src, dst) })
+}
+
+func (s *SyncTree) Sort() {
+	s.update(func(t *Tree) error {
+		t.Sort()
+		return nil
+	})
+}
+
+func (s *SyncTree) SortBy(ks []string, less func(a, b Entry) bool) {
+	s.change(ks, func(t *Tree) error {
+		t.SortBy(ks, less)
+		return nil
+	})
+}
+
+func (s *SyncTree) Set(ks []string, v interface{}) {
+	s.change(ks, func(t *Tree) error {
+		t.Set(ks, v)
+		return nil
+	})
+}
+
+func (s *SyncTree) Replace(ks []string, v interface{}) {
+	s.change(ks, func(t *Tree) error {
+		t.Replace(ks, v)
+		return nil
+	})
+}
+
+func (s *SyncTree) SetFunc(ks []string, fn func(old interface{}) interface{}) {
+	s.change(ks, func(t *Tree) error {
+		t.SetFunc(ks, fn)
+		return nil
+	})
+}
+
+// Walk runs t.Walk over a copy of the tree, which replaces the current
+// version once the walk is over
+func (s *SyncTree) Walk(fn WalkFunc) {
+	s.update(func(t *Tree) error {
+		t.Walk(fn)
+		return nil
+	})
+}
+
+func (s *SyncTree) WalkPostOrder(fn WalkFunc) {
+	s.update(func(t *Tree) error {
+		t.WalkPostOrder(fn)
+		return nil
+	})
+}
+
+// InsertAt, RemoveAt, Push, Pop and Reindex leave the tree unchanged when
+// they fail
+func (s *SyncTree) InsertAt(ks []string, i int, v interface{}) error {
+	return s.change(ks, func(t *Tree) error { return t.InsertAt(ks, i, v) })
+}
+
+func (s *SyncTree) RemoveAt(ks []string, i int) (interface{}, error) {
+	var res interface{}
+	err := s.change(ks, func(t *Tree) (err error) {
+		res, err = t.RemoveAt(ks, i)
+		return err
+	})
+	return res, err
+}
+
+func (s *SyncTree) Push(ks []string, v interface{}) error {
+	return s.change(ks, func(t *Tree) error { return t.Push(ks, v) })
+}
+
+func (s *SyncTree) Pop(ks []string) (interface{}, error) {
+	var res interface{}
+	err := s.change(ks, func(t *Tree) (err error) {
+		res, err = t.Pop(ks)
+		return err
+	})
+	return res, err
+}
+
+func (s *SyncTree) Reindex(ks []string) error {
+	return s.change(ks, func(t *Tree) error { return t.Reindex(ks) })
+}
+
+func (s *SyncTree) derive(fn func(*Persistent) *Persistent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.p.Store(fn(s.p.Load()))
+}
+
+// change applies fn to a new version of the tree in which the nodes matching
+// the pattern ks have been copied. The new version is only published if fn
+// succeeds.
+func (s *SyncTree) change(ks []string, fn func(*Tree) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var err error
+	p := s.p.Load().derive(func(c copier, t *Tree) {
+		c.path(t.root, ks)
+		err = fn(t)
+	})
+	if err != nil {
+		return err
+	}
+	s.p.Store(p)
+	return nil
+}
+
+// update behaves like change, but it copies the whole tree
+func (s *SyncTree) update(fn func(*Tree) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.p.Load().Tree()
+	if err := fn(t); err != nil {
+		return err
+	}
+	s.p.Store(&Persistent{t: t})
+	return nil
+}
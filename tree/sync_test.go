@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestSyncTree(t *testing.T) {
+	s, _ := NewSyncTree(map[string]interface{}{
+		"routes": []interface{}{},
+		"config": map[string]interface{}{"a": 1},
+	})
+	snapshot := s.Snapshot()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				k := fmt.Sprintf("k%d", i)
+				s.Add([]string{"config", k}, j)
+				s.Move([]string{"config", k}, []string{"config", k + "-moved"})
+				s.Del([]string{"config", k + "-moved"})
+				if err := s.Push([]string{"routes"}, j); err != nil {
+					t.Error(err)
+				}
+				s.Set([]string{"config", "a"}, j)
+				s.SetFunc([]string{"routes", "0"}, func(old interface{}) interface{} { return old })
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if _, ok := s.Lookup([]string{"config", "a"}); !ok {
+					t.Error("missing value")
+				}
+				s.Find([]string{"routes", "*"})
+				for range s.Snapshot().Leaves() {
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if v, err := s.GetSlice([]string{"routes"}); err != nil || len(v) != 400 {
+		t.Errorf("unexpected routes: %d %v", len(v), err)
+	}
+	if v := s.Get([]string{"config"}); len(v.(map[string]interface{})) != 1 {
+		t.Errorf("unexpected config: %v", v)
+	}
+
+	expected := map[string]interface{}{
+		"routes": []interface{}{},
+		"config": map[string]interface{}{"a": 1},
+	}
+	if v := snapshot.Get([]string{}); !reflect.DeepEqual(v, expected) {
+		t.Errorf("the snapshot has changed: %v", v)
+	}
+}
+
+func TestSyncTree_failure(t *testing.T) {
+	s, _ := NewSyncTree(map[string]interface{}{"a": []interface{}{1}})
+	before := s.Snapshot()
+
+	if err := s.InsertAt([]string{"a"}, 5, 2); err != ErrIndexOutOfRange {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := s.Pop([]string{"b"}); err != ErrNotFound {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if s.Snapshot() != before {
+		t.Error("a failed operation has published a new version")
+	}
+
+	if v, err := s.Pop([]string{"a"}); err != nil || v != 1 {
+		t.Errorf("unexpected result: %v %v", v, err)
+	}
+	if v := before.Get([]string{"a"}); !reflect.DeepEqual(v, []interface{}{1}) {
+		t.Errorf("the snapshot has changed: %v", v)
+	}
+}
@@ -20,15 +20,37 @@ import (
 	"errors"
 )
 
-const wildcard = "*"
+const (
+	wildcard = "*"
+	// appendLabel adds a new element to the end of a collection when used
+	// in the destination of a move
+	appendLabel = "[]"
+)
 
 var errNoNilValuesAllowed = errors.New("no nil values allowed")
 
 type Tree struct {
-	root *node
+	root          *node
+	autoVivify    bool
+	subscriptions []*Subscription
+	// tx is the transaction in progress, which the nodes are copied for
+	// before changing them
+	tx *Tx
+}
+
+// Option customizes the behaviour of a Tree
+type Option func(*Tree)
+
+// WithAutoVivify makes Add create collections instead of objects for the
+// missing nodes followed by an index, padding the collections with null
+// elements up to the index written
+func WithAutoVivify() Option {
+	return func(t *Tree) {
+		t.autoVivify = true
+	}
 }
 
-func New(v interface{}) (*Tree, error) {
+func New(v interface{}, opts ...Option) (*Tree, error) {
 	if v == nil {
 		return nil, errNoNilValuesAllowed
 	}
@@ -37,6 +59,10 @@ func New(v interface{}) (*Tree, error) {
 		root: &node{},
 	}
 
+	for _, opt := range opts {
+		opt(tr)
+	}
+
 	tr.Add([]string{}, v)
 
 	return tr, nil
@@ -46,14 +72,33 @@ func (t *Tree) Add(ks []string, v interface{}) {
 	if v == nil {
 		return
 	}
-	t.root.Add(ks, v)
+	if t.tx != nil {
+		t.tx.c.add(t, ks, v)
+	}
+	t.observe(func() {
+		if t.autoVivify {
+			t.root.vivify(ks, v)
+			return
+		}
+		t.root.Add(ks, v)
+	}, ks)
 }
 
 func (t *Tree) Del(ks []string) {
-	t.root.Del(ks...)
+	if t.tx != nil {
+		t.tx.c.del(t, ks)
+	}
+	t.observe(func() { t.root.Del(ks...) }, ks)
 }
 
 func (t *Tree) Append(src, dst []string) {
+	if t.tx != nil {
+		t.tx.c.append(t, src, dst)
+	}
+	t.observe(func() { t.appendCollection(src, dst) }, src, dst)
+}
+
+func (t *Tree) appendCollection(src, dst []string) {
 	elements1, ok := t.root.Get(src...).([]interface{})
 	if !ok {
 		return
@@ -72,73 +117,105 @@ func (t *Tree) Get(ks []string) interface{} {
 	return t.root.Get(ks...)
 }
 
+// Move relocates the subtrees matching src to dst. The wildcards in dst are
+// replaced, in order, by the labels matched by the wildcards in src, and a
+// wildcard as the last segment of src moves every child of the matched
+// nodes. Missing intermediate nodes are created as collections when the next
+// segment is an index and as objects otherwise. When dst has more wildcards
+// than src the move is ignored, and when it has fewer, the matches sharing a
+// destination replace each other.
+//
+// Moving into a collection inserts the subtree in the position given by the
+// last segment of dst, or appends it if that segment is not an index or it
+// is "[]". The elements of the collections are relabeled with their
+// positions after every move.
 func (t *Tree) Move(src, dst []string) {
+	if t.tx != nil {
+		t.tx.c.move(t, src, dst)
+	}
+	t.observe(func() { t.move(src, dst) }, src, dst)
+}
+
+func (t *Tree) move(src, dst []string) {
+	lenSrc, lenDst := len(src), len(dst)
+	if lenSrc == 0 || lenDst == 0 || wildcards(dst) > wildcards(src) {
+		return
+	}
+
 	next := []nodeAndPath{{n: t.root, p: []string{}}}
+	last := src[lenSrc-1]
 
-	prefixLen := len(src)
+	if lenSrc == lenDst && equalPaths(src[:lenSrc-1], dst[:lenDst-1]) {
+		// every match stays under the same parent, so it is just relabeled
+		if lenSrc > 1 {
+			next = t.collectMoveCandidates(src[:lenSrc-1], next, false)
+		}
+		t.relabelEdges(next, last, dst[lenDst-1])
+		return
+	}
 
-	if prefixLen > 1 {
-		next = t.collectMoveCandidates(src[:prefixLen-1], next)
+	if lenSrc > 1 {
+		next = t.collectMoveCandidates(src[:lenSrc-1], next, true)
 	}
 
 	var edgesToMove []edgeToMove
-	lenDst := len(dst)
-
-	isEdgeRelabel := prefixLen == lenDst
-
 	for _, nap := range next {
-		for i, e := range nap.n.edges {
-			if e.label != src[prefixLen-1] {
-				continue
+		if last != wildcard {
+			if e := nap.n.child(last); e != nil {
+				edgesToMove = append(edgesToMove, newEdgeToMove(nap, e, src, dst))
 			}
-
-			if isEdgeRelabel {
-				e.label = dst[prefixLen-1]
-				break
-			}
-
-			edgesToMove = append(edgesToMove, edgeToMove{nodeAndPath: nap, e: e})
-
-			copy(nap.n.edges[i:], nap.n.edges[i+1:])
-			nap.n.edges[len(nap.n.edges)-1] = nil
-			nap.n.edges = nap.n.edges[:len(nap.n.edges)-1]
-
-			break
+			continue
+		}
+		for _, e := range nap.n.edges {
+			edgesToMove = append(edgesToMove, newEdgeToMove(nap, e, src, dst))
 		}
 	}
 
-	if isEdgeRelabel {
-		return
+	// edges staying under the same parent are just relabeled; the rest are
+	// detached before being attached to their destination, so matches
+	// pointing into other moved subtrees are not affected
+	var relabeled, detached []edgeToMove
+	for _, em := range edgesToMove {
+		if !t.root.accepts(em.dst) {
+			continue
+		}
+		if equalPaths(em.p, em.dst[:lenDst-1]) {
+			relabeled = append(relabeled, em)
+			continue
+		}
+		em.n.removeEdge(em.e)
+		detached = append(detached, em)
 	}
 
-	if prefixLen > lenDst {
-		t.promoteEdges(edgesToMove, dst)
-		return
+	for _, em := range relabeled {
+		em.n.setEdge(em.dst[lenDst-1], em.e)
 	}
 
-	t.embeddingEdges(edgesToMove, dst[prefixLen-1:])
+	for _, em := range detached {
+		t.root.ensure(em.dst).addEdge(em.dst[lenDst-1], em.e)
+	}
 }
 
 func (t *Tree) Sort() {
+	if t.tx != nil {
+		t.tx.c.all(t.root)
+	}
 	t.root.sort()
 }
 
-func (t *Tree) collectMoveCandidates(src []string, next []nodeAndPath) []nodeAndPath {
+func (t *Tree) collectMoveCandidates(src []string, next []nodeAndPath, withPaths bool) []nodeAndPath {
 	var acc []nodeAndPath
 	for _, step := range src {
 		if step == wildcard {
 			for _, nap := range next {
 				for _, e := range nap.n.edges {
-					acc = append(acc, nodeAndPath{n: e.n, p: append(nap.p, e.label)})
+					acc = append(acc, nap.next(e, withPaths))
 				}
 			}
 		} else {
 			for _, nap := range next {
-				for _, e := range nap.n.edges {
-					if step == e.label {
-						acc = append(acc, nodeAndPath{n: e.n, p: append(nap.p, e.label)})
-						break
-					}
+				if e := nap.n.child(step); e != nil {
+					acc = append(acc, nap.next(e, withPaths))
 				}
 			}
 		}
@@ -147,64 +224,75 @@ func (t *Tree) collectMoveCandidates(src []string, next []nodeAndPath) []nodeAnd
 	return next
 }
 
-func (t *Tree) promoteEdges(edgesToMove []edgeToMove, dst []string) {
-	var l string
-	lenDst := len(dst)
-	for _, n := range edgesToMove {
-		parent := t.root
-		for i, path := range dst[:lenDst-1] {
-			if path == wildcard {
-				l = n.p[i]
-			} else {
-				l = path
+func (t *Tree) relabelEdges(next []nodeAndPath, src, dst string) {
+	for _, nap := range next {
+		if dst == appendLabel && !nap.n.isCollection {
+			continue
+		}
+		if src != wildcard {
+			if e := nap.n.child(src); e != nil {
+				nap.n.setEdge(dst, e)
 			}
+			continue
+		}
+		if dst == wildcard {
+			continue
+		}
+		if k := len(nap.n.edges); k > 0 {
+			e := nap.n.edges[k-1]
+			nap.n.removeEdges(0, k-1)
+			nap.n.setEdge(dst, e)
+		}
+	}
+}
 
-			found := false
-			for _, e := range parent.edges {
-				if e.label != l {
-					continue
-				}
-				found = true
-				parent = e.n
-				break
-			}
+func newEdgeToMove(nap nodeAndPath, e *edge, src, dst []string) edgeToMove {
+	matched := appendPath(nap.p, e.label)
+	var labels []string
+	for i, k := range src {
+		if k == wildcard {
+			labels = append(labels, matched[i])
+		}
+	}
 
-			if !found {
-				break
-			}
+	target := make([]string, len(dst))
+	for i, k := range dst {
+		if k == wildcard {
+			k, labels = labels[0], labels[1:]
 		}
+		target[i] = k
+	}
+
+	return edgeToMove{nodeAndPath: nap, e: e, dst: target}
+}
+
+func appendPath(p []string, k string) []string {
+	res := make([]string, len(p)+1)
+	copy(res, p)
+	res[len(p)] = k
+	return res
+}
 
-		n.e.n.SetDepth(parent.depth + 1)
-		n.e.label = dst[lenDst-1]
-		parent.edges = append(parent.edges, n.e)
+func equalPaths(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
 	}
+	return true
 }
 
-func (t *Tree) embeddingEdges(edgesToMove []edgeToMove, dst []string) {
-	lenDst := len(dst)
-	for _, em := range edgesToMove {
-		root := em.n
-		for _, k := range dst[:lenDst-1] {
-			found := false
-			for _, e := range root.edges {
-				if e.label != k {
-					continue
-				}
-				found = true
-				root = e.n
-				break
-			}
-			if found {
-				continue
-			}
-			child := newNode(root.depth + 1)
-			root.edges = append(root.edges, &edge{label: k, n: child})
-			root = child
+func wildcards(ks []string) int {
+	res := 0
+	for _, k := range ks {
+		if k == wildcard {
+			res++
 		}
-		em.e.label = dst[lenDst-1]
-		em.e.n.SetDepth(root.depth + 1)
-		root.edges = append(root.edges, em.e)
 	}
+	return res
 }
 
 type nodeAndPath struct {
@@ -212,7 +300,15 @@ type nodeAndPath struct {
 	p []string
 }
 
+func (nap nodeAndPath) next(e *edge, withPaths bool) nodeAndPath {
+	if !withPaths {
+		return nodeAndPath{n: e.n}
+	}
+	return nodeAndPath{n: e.n, p: appendPath(nap.p, e.label)}
+}
+
 type edgeToMove struct {
 	nodeAndPath
-	e *edge
+	e   *edge
+	dst []string
 }
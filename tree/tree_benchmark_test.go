@@ -17,7 +17,9 @@
 package tree
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"testing"
 )
 
@@ -105,6 +107,137 @@ func BenchmarkMove(b *testing.B) {
 	_ = res
 }
 
+func BenchmarkMoveSubtree(b *testing.B) {
+	for _, size := range []int{1, 5, 50, 500} {
+		b.Run(fmt.Sprintf("%d", size), func(b *testing.B) {
+			tr, _ := New(getInputData(size))
+
+			b.ResetTimer()
+
+			for n := 0; n < b.N; n++ {
+				if n%2 == 0 {
+					tr.Move([]string{"a"}, []string{"x", "y", "a"})
+				} else {
+					tr.Move([]string{"x", "y", "a"}, []string{"a"})
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkWideNew(b *testing.B) {
+	var res *Tree
+
+	for _, size := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("%d", size), func(b *testing.B) {
+			in := getWideInputData(size)
+
+			b.ResetTimer()
+
+			for n := 0; n < b.N; n++ {
+				res, _ = New(in)
+			}
+		})
+	}
+	_ = res
+}
+
+func BenchmarkWideGet(b *testing.B) {
+	var res interface{}
+
+	for _, size := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("%d", size), func(b *testing.B) {
+			tr, _ := New(getWideInputData(size))
+			keys := wideKeys(size)
+
+			b.ResetTimer()
+
+			for n := 0; n < b.N; n++ {
+				res = tr.Get([]string{"ids", keys[n%size], "id"})
+			}
+		})
+	}
+	_ = res
+}
+
+func BenchmarkWideAddDel(b *testing.B) {
+	for _, size := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("%d", size), func(b *testing.B) {
+			tr, _ := New(getWideInputData(size))
+			keys := wideKeys(size)
+
+			b.ResetTimer()
+
+			for n := 0; n < b.N; n++ {
+				k := keys[n%size]
+				tr.Del([]string{"ids", k})
+				tr.Add([]string{"ids", k, "id"}, n)
+			}
+		})
+	}
+}
+
+func BenchmarkWideMove(b *testing.B) {
+	for _, size := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("%d", size), func(b *testing.B) {
+			tr, _ := New(getWideInputData(size))
+			keys := wideKeys(size)
+
+			b.ResetTimer()
+
+			for n := 0; n < b.N; n++ {
+				k := keys[n%size]
+				tr.Move([]string{"ids", k, "id"}, []string{"ids", k, "key"})
+				tr.Move([]string{"ids", k, "key"}, []string{"ids", k, "id"})
+			}
+		})
+	}
+}
+
+func BenchmarkWriteJSON(b *testing.B) {
+	for _, size := range []int{1, 5, 50, 500} {
+		b.Run(fmt.Sprintf("%d", size), func(b *testing.B) {
+			tr, _ := New(getInputData(size))
+
+			b.ResetTimer()
+
+			for n := 0; n < b.N; n++ {
+				tr.WriteJSON(io.Discard, JSONOptions{})
+			}
+		})
+	}
+}
+
+func BenchmarkMarshalExpanded(b *testing.B) {
+	for _, size := range []int{1, 5, 50, 500} {
+		b.Run(fmt.Sprintf("%d", size), func(b *testing.B) {
+			tr, _ := New(getInputData(size))
+
+			b.ResetTimer()
+
+			for n := 0; n < b.N; n++ {
+				json.NewEncoder(io.Discard).Encode(tr.Get([]string{}))
+			}
+		})
+	}
+}
+
+func wideKeys(size int) []string {
+	keys := make([]string, size)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("id-%d", i)
+	}
+	return keys
+}
+
+func getWideInputData(size int) map[string]interface{} {
+	ids := make(map[string]interface{}, size)
+	for i, k := range wideKeys(size) {
+		ids[k] = map[string]interface{}{"id": i}
+	}
+	return map[string]interface{}{"ids": ids}
+}
+
 func getInputData(size int) map[string]interface{} {
 	first := map[string]interface{}{
 		"b": []interface{}{
@@ -17,63 +17,11 @@
 package tree
 
 import (
-	"bytes"
-	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 )
 
-const tabSize = 4
-
-var sb = new(strings.Builder)
-
-func (n *node) writeTo(bd *strings.Builder) {
-	for i, e := range n.edges {
-		e.writeTo(bd, []bool{i == len(n.edges)-1})
-	}
-}
-
-func (e *edge) writeTo(bd *strings.Builder, tabList []bool) {
-	length := len(tabList)
-	isLast, tlist := tabList[length-1], tabList[:length-1]
-	for _, hasTab := range tlist {
-		if hasTab {
-			bd.Write(bytes.Repeat([]byte(" "), tabSize))
-			continue
-		}
-		bd.WriteRune('│')
-		bd.Write(bytes.Repeat([]byte(" "), tabSize-1))
-	}
-	if !isLast {
-		bd.WriteRune('├')
-	} else {
-		bd.WriteRune('└')
-	}
-	bd.WriteString("── ")
-	bd.WriteString(e.label)
-	if e.n.IsLeaf() {
-		fmt.Fprintf(bd, "\t%+v", e.n.Value)
-	} else if e.n.isCollection {
-		bd.WriteString(" []")
-	}
-	bd.WriteByte('\n')
-	for i, next := range e.n.edges {
-		if len(tabList) < next.n.depth {
-			tabList = append(tabList, i == len(e.n.edges)-1)
-		} else {
-			tabList[next.n.depth-1] = i == len(e.n.edges)-1
-		}
-		next.writeTo(bd, tabList)
-	}
-}
-
-func (t *Tree) String() string {
-	sb.Reset()
-	sb.WriteByte('\n')
-	t.root.writeTo(sb)
-	return sb.String()
-}
-
 func TestTree_Del(t *testing.T) {
 	for _, tc := range []struct {
 		name    string
@@ -395,6 +343,109 @@ func TestTree_Del(t *testing.T) {
 │   └── 11
 │       └── aa	1
 └── turbo	false
+`,
+		},
+		{
+			name:    "negative_index",
+			pattern: "a.-1",
+			in: map[string]interface{}{
+				"a": []interface{}{1, 2, 3},
+			},
+			out: `
+└── a []
+    ├── 0	1
+    └── 1	2
+`,
+		},
+		{
+			name:    "negative_index_attribute",
+			pattern: "a.-2.b",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"b": 1, "c": 1},
+					map[string]interface{}{"b": 2, "c": 2},
+					map[string]interface{}{"b": 3, "c": 3},
+				},
+			},
+			out: `
+└── a []
+    ├── 0
+    │   ├── b	1
+    │   └── c	1
+    ├── 1
+    │   └── c	2
+    └── 2
+        ├── b	3
+        └── c	3
+`,
+		},
+		{
+			name:    "negative_index_out_of_range",
+			pattern: "a.-4",
+			in: map[string]interface{}{
+				"a": []interface{}{1, 2, 3},
+			},
+			out: `
+└── a []
+    ├── 0	1
+    ├── 1	2
+    └── 2	3
+`,
+		},
+		{
+			name:    "negative_index_in_struct",
+			pattern: "a.-1",
+			in: map[string]interface{}{
+				"a": map[string]interface{}{"-1": 1, "b": 2},
+			},
+			out: `
+└── a
+    └── b	2
+`,
+		},
+		{
+			name:    "collection_element",
+			pattern: "a.1",
+			in: map[string]interface{}{
+				"a": []interface{}{1, 2, 3},
+			},
+			out: `
+└── a []
+    ├── 0	1
+    └── 1	3
+`,
+		},
+		{
+			name:    "slice_range",
+			pattern: "a.1:3",
+			in: map[string]interface{}{
+				"a": []interface{}{1, 2, 3, 4},
+			},
+			out: `
+└── a []
+    ├── 0	1
+    └── 1	4
+`,
+		},
+		{
+			name:    "slice_range_open",
+			pattern: "a.:-1.b",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"b": 1, "c": 1},
+					map[string]interface{}{"b": 2, "c": 2},
+					map[string]interface{}{"b": 3, "c": 3},
+				},
+			},
+			out: `
+└── a []
+    ├── 0
+    │   └── c	1
+    ├── 1
+    │   └── c	2
+    └── 2
+        ├── b	3
+        └── c	3
 `,
 		},
 	} {
@@ -403,7 +454,7 @@ func TestTree_Del(t *testing.T) {
 			res.Sort()
 
 			res.Del(strings.Split(tc.pattern, "."))
-			tree := res.String()
+			tree := "\n" + res.String()
 			if tree != tc.out {
 				t.Errorf("unexpected result (%s):'%s'\n'%s'", tc.pattern, tree, tc.out)
 			}
@@ -491,6 +542,156 @@ func TestTree_Get(t *testing.T) {
 	}
 }
 
+func TestTree_Get_span(t *testing.T) {
+	in := map[string]interface{}{
+		"a": []interface{}{
+			map[string]interface{}{"b": 0},
+			map[string]interface{}{"b": 1},
+			map[string]interface{}{"b": 2},
+			map[string]interface{}{"b": 3},
+		},
+		"c": map[string]interface{}{"-1": true},
+	}
+	tree, _ := New(in)
+
+	for _, tc := range []struct {
+		pattern string
+		out     interface{}
+	}{
+		{pattern: "a.-1.b", out: 3},
+		{pattern: "a.-4.b", out: 0},
+		{pattern: "a.-5.b", out: nil},
+		{pattern: "a.1:3.b", out: []interface{}{1, 2}},
+		{pattern: "a.:2.b", out: []interface{}{0, 1}},
+		{pattern: "a.-2:.b", out: []interface{}{2, 3}},
+		{pattern: "a.3:1.b", out: []interface{}{}},
+		{pattern: "c.-1", out: true},
+	} {
+		v := tree.Get(strings.Split(tc.pattern, "."))
+		if !reflect.DeepEqual(v, tc.out) {
+			t.Errorf("unexpected result (%s): %v", tc.pattern, v)
+		}
+	}
+}
+
+func TestTree_Add(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []Option
+		out  interface{}
+	}{
+		{
+			name: "objects",
+			out: map[string]interface{}{
+				"items": map[string]interface{}{
+					"0": map[string]interface{}{"name": "a"},
+					"2": map[string]interface{}{"name": "c"},
+				},
+			},
+		},
+		{
+			name: "auto_vivify",
+			opts: []Option{WithAutoVivify()},
+			out: map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"name": "a"},
+					nil,
+					map[string]interface{}{"name": "c"},
+				},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr, _ := New(map[string]interface{}{}, tc.opts...)
+			tr.Add([]string{"items", "0", "name"}, "a")
+			tr.Add([]string{"items", "2", "name"}, "c")
+
+			if v := tr.Get([]string{}); !reflect.DeepEqual(v, tc.out) {
+				t.Errorf("unexpected result: %v", v)
+			}
+		})
+	}
+}
+
+func TestTree_Add_autoVivifyNested(t *testing.T) {
+	tr, _ := New(map[string]interface{}{"a": []interface{}{1}}, WithAutoVivify())
+	tr.Add([]string{"a", "3", "1"}, true)
+	tr.Add([]string{"a", "3", "0"}, false)
+	tr.Add([]string{"a", "-1", "2"}, 42)
+
+	expected := []interface{}{1, nil, nil, []interface{}{false, true, 42}}
+	if v := tr.Get([]string{"a"}); !reflect.DeepEqual(v, expected) {
+		t.Errorf("unexpected result: %v", v)
+	}
+}
+
+func TestTree_Add_autoVivifyPadding(t *testing.T) {
+	tr, _ := New(map[string]interface{}{"items": []interface{}{}, "x": nil}, WithAutoVivify())
+	tr.Add([]string{"items", "2", "name"}, "x")
+	tr.Add([]string{"items", "1", "0", "x"}, "v")
+	tr.Add([]string{"x", "1"}, true)
+
+	expected := map[string]interface{}{
+		"items": []interface{}{
+			nil,
+			[]interface{}{map[string]interface{}{"x": "v"}},
+			map[string]interface{}{"name": "x"},
+		},
+		"x": []interface{}{nil, true},
+	}
+	if v := tr.Get([]string{}); !reflect.DeepEqual(v, expected) {
+		t.Errorf("unexpected result: %v", v)
+	}
+}
+
+func TestTree_wideObject(t *testing.T) {
+	tr, _ := New(getWideInputData(100))
+
+	tr.Del([]string{"ids", "id-10"})
+	tr.Move([]string{"ids", "id-20"}, []string{"ids", "id-200"})
+	tr.Move([]string{"ids", "id-30"}, []string{"ids", "id-40"})
+	tr.Move([]string{"ids", "id-50"}, []string{"moved"})
+	tr.Set([]string{"ids", "id-60", "id"}, "x")
+	tr.Add([]string{"ids", "id-300", "id"}, 300)
+	tr.Walk(func(path []string, _ interface{}, _ bool) WalkAction {
+		if len(path) == 2 && path[1] == "id-70" {
+			return Delete
+		}
+		return Continue
+	})
+
+	for k, v := range map[string]interface{}{
+		"id-0":   0,
+		"id-10":  nil,
+		"id-20":  nil,
+		"id-200": 20,
+		"id-30":  nil,
+		"id-40":  30,
+		"id-50":  nil,
+		"id-60":  "x",
+		"id-70":  nil,
+		"id-300": 300,
+		"id-99":  99,
+	} {
+		if res := tr.Get([]string{"ids", k, "id"}); res != v {
+			t.Errorf("unexpected value for %s: %v", k, res)
+		}
+	}
+	if res := tr.Get([]string{"moved", "id"}); res != 50 {
+		t.Errorf("unexpected value for the moved key: %v", res)
+	}
+
+	n := tr.root.find("ids")
+	if len(n.index) != len(n.edges) || len(n.edges) != 97 {
+		t.Errorf("unexpected size of the index: %d edges, %d indexed", len(n.edges), len(n.index))
+	}
+	for _, e := range n.edges {
+		if n.index[e.label] != e {
+			t.Errorf("edge %s not indexed", e.label)
+		}
+	}
+}
+
 func TestTree_Move(t *testing.T) {
 	for _, tc := range []struct {
 		name string
@@ -765,19 +966,300 @@ func TestTree_Move(t *testing.T) {
 │               └── x
 │                   └── a	1
 └── turbo	false
+`,
+		},
+		{
+			name: "to_another_struct",
+			src:  "a.x",
+			dst:  "b.y",
+			in: map[string]interface{}{
+				"a": map[string]interface{}{"x": 1, "z": 2},
+				"b": map[string]interface{}{"c": 3},
+			},
+			out: `
+├── a
+│   └── z	2
+└── b
+    ├── c	3
+    └── y	1
+`,
+		},
+		{
+			name: "missing_intermediates",
+			src:  "a.b.c",
+			dst:  "x.y",
+			in: map[string]interface{}{
+				"a": map[string]interface{}{
+					"b": map[string]interface{}{"c": 1, "d": 2},
+				},
+			},
+			out: `
+├── a
+│   └── b
+│       └── d	2
+└── x
+    └── y	1
+`,
+		},
+		{
+			name: "missing_intermediate_collection",
+			src:  "a",
+			dst:  "x.0.y",
+			in:   map[string]interface{}{"a": 1, "b": 2},
+			out: `
+├── b	2
+└── x []
+    └── 0
+        └── y	1
+`,
+		},
+		{
+			name: "replacing_existing",
+			src:  "a.b",
+			dst:  "c",
+			in: map[string]interface{}{
+				"a": map[string]interface{}{"b": 1},
+				"c": 2,
+			},
+			out: `
+├── a	<nil>
+└── c	1
+`,
+		},
+		{
+			name: "every_child",
+			src:  "a.*",
+			dst:  "b.*",
+			in: map[string]interface{}{
+				"a": map[string]interface{}{"x": 1, "y": 2},
+				"b": map[string]interface{}{"z": 3},
+			},
+			out: `
+├── a	<nil>
+└── b
+    ├── x	1
+    ├── y	2
+    └── z	3
+`,
+		},
+		{
+			name: "every_element",
+			src:  "a.*",
+			dst:  "b.*.x",
+			in: map[string]interface{}{
+				"a": []interface{}{1, 2},
+			},
+			out: `
+├── a	[]
+└── b []
+    ├── 0
+    │   └── x	1
+    └── 1
+        └── x	2
+`,
+		},
+		{
+			name: "every_child_in_nested_structs",
+			src:  "a.*.*",
+			dst:  "a.*.*.v",
+			in: map[string]interface{}{
+				"a": map[string]interface{}{
+					"x": map[string]interface{}{"m": 1},
+					"y": map[string]interface{}{"n": 2},
+				},
+			},
+			out: `
+└── a
+    ├── x
+    │   └── m
+    │       └── v	1
+    └── y
+        └── n
+            └── v	2
+`,
+		},
+		{
+			name: "fewer_wildcards_in_dst",
+			src:  "a.*.b",
+			dst:  "c",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"b": 1},
+					map[string]interface{}{"b": 2},
+				},
+			},
+			out: `
+├── a []
+│   ├── 0	<nil>
+│   └── 1	<nil>
+└── c	2
+`,
+		},
+		{
+			name: "every_child_to_a_single_label",
+			src:  "a.*",
+			dst:  "a.z",
+			in: map[string]interface{}{
+				"a": map[string]interface{}{"x": 1, "y": 2},
+			},
+			out: `
+└── a
+    └── z	2
+`,
+		},
+		{
+			name: "into_collection",
+			src:  "x",
+			dst:  "a.1",
+			in: map[string]interface{}{
+				"a": []interface{}{0, 1},
+				"x": 42,
+			},
+			out: `
+└── a []
+    ├── 0	0
+    ├── 1	42
+    └── 2	1
+`,
+		},
+		{
+			name: "into_collection_out_of_range",
+			src:  "x",
+			dst:  "a.7",
+			in: map[string]interface{}{
+				"a": []interface{}{0, 1},
+				"x": 42,
+			},
+			out: `
+└── a []
+    ├── 0	0
+    ├── 1	1
+    └── 2	42
+`,
+		},
+		{
+			name: "append_to_collection",
+			src:  "b.*.x",
+			dst:  "a.[]",
+			in: map[string]interface{}{
+				"a": []interface{}{0},
+				"b": []interface{}{
+					map[string]interface{}{"x": 1},
+					map[string]interface{}{"x": 2},
+				},
+			},
+			out: `
+├── a []
+│   ├── 0	0
+│   ├── 1	1
+│   └── 2	2
+└── b []
+    ├── 0	<nil>
+    └── 1	<nil>
+`,
+		},
+		{
+			name: "append_to_missing_collection",
+			src:  "b.*.x",
+			dst:  "c.[].y",
+			in: map[string]interface{}{
+				"b": []interface{}{
+					map[string]interface{}{"x": 1, "z": 1},
+					map[string]interface{}{"x": 2},
+				},
+			},
+			out: `
+├── b []
+│   ├── 0
+│   │   └── z	1
+│   └── 1	<nil>
+└── c []
+    ├── 0
+    │   └── y	1
+    └── 1
+        └── y	2
+`,
+		},
+		{
+			name: "append_to_object",
+			src:  "x",
+			dst:  "a.[]",
+			in: map[string]interface{}{
+				"a": map[string]interface{}{"b": 1},
+				"x": 42,
+			},
+			out: `
+├── a
+│   └── b	1
+└── x	42
+`,
+		},
+		{
+			name: "within_collection",
+			src:  "a.0",
+			dst:  "a.2",
+			in: map[string]interface{}{
+				"a": []interface{}{0, 1, 2, 3},
+			},
+			out: `
+└── a []
+    ├── 0	1
+    ├── 1	2
+    ├── 2	0
+    └── 3	3
+`,
+		},
+		{
+			name: "to_the_end_of_collection",
+			src:  "a.0",
+			dst:  "a.[]",
+			in: map[string]interface{}{
+				"a": []interface{}{0, 1, 2},
+			},
+			out: `
+└── a []
+    ├── 0	1
+    ├── 1	2
+    └── 2	0
+`,
+		},
+		{
+			name: "out_of_collection",
+			src:  "a.0",
+			dst:  "b",
+			in: map[string]interface{}{
+				"a": []interface{}{0, 1, 2},
+			},
+			out: `
+├── a []
+│   ├── 0	1
+│   └── 1	2
+└── b	0
+`,
+		},
+		{
+			name: "more_wildcards_in_dst",
+			src:  "a.b",
+			dst:  "*.c",
+			in: map[string]interface{}{
+				"a": map[string]interface{}{"b": 1},
+			},
+			out: `
+└── a
+    └── b	1
 `,
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			res, _ := New(tc.in)
 			res.Sort()
-			original := res.String()
+			original := "\n" + res.String()
 
 			res.Move(strings.Split(tc.src, "."), strings.Split(tc.dst, "."))
 
 			res.Sort()
 
-			if tree := res.String(); tree != tc.out {
+			if tree := "\n" + res.String(); tree != tc.out {
 				t.Errorf("unexpected result (%s -> %s) from:%s\nhave:%s\nwant:%s", tc.src, tc.dst, original, tree, tc.out)
 			}
 		})
@@ -807,13 +1289,13 @@ func TestTree_Append(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			res, _ := New(tc.in)
 			res.Sort()
-			original := res.String()
+			original := "\n" + res.String()
 
 			res.Append(strings.Split(tc.src, "."), strings.Split(tc.dst, "."))
 
 			res.Sort()
 
-			if tree := res.String(); tree != tc.out {
+			if tree := "\n" + res.String(); tree != tc.out {
 				t.Errorf("unexpected result (%s -> %s) from:%s\nhave:%s\nwant:%s", tc.src, tc.dst, original, tree, tc.out)
 			}
 		})
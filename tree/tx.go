@@ -0,0 +1,101 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import "errors"
+
+var errTxDone = errors.New("transaction already committed or rolled back")
+
+// Tx groups changes to a tree so they can be undone together. The nodes are
+// copied before a change touches them, so the tree as it was when the
+// transaction began is kept aside until the transaction finishes.
+type Tx struct {
+	t    *Tree
+	root *node
+	c    copier
+	// paths are the paths changed during the transaction, which rolling it
+	// back changes again
+	paths [][]string
+	// outer is the transaction in progress when this one began, which goes
+	// on once this one finishes
+	outer *Tx
+}
+
+// Begin starts a transaction on the tree. The changes are visible in the
+// tree right away, and the ones made directly on the tree until the
+// transaction is committed or rolled back are part of it too.
+func (t *Tree) Begin() *Tx {
+	tx := &Tx{t: t, root: t.root, c: copier{}, outer: t.tx}
+	t.root = tx.c.fresh(t.root)
+	t.tx = tx
+	return tx
+}
+
+// Add, Del, Move and Append behave like the methods of the tree. They are
+// ignored once the transaction is finished.
+func (tx *Tx) Add(ks []string, v interface{}) {
+	if tx.t != nil {
+		tx.t.Add(ks, v)
+	}
+}
+
+func (tx *Tx) Del(ks []string) {
+	if tx.t != nil {
+		tx.t.Del(ks)
+	}
+}
+
+func (tx *Tx) Move(src, dst []string) {
+	if tx.t != nil {
+		tx.t.Move(src, dst)
+	}
+}
+
+func (tx *Tx) Append(src, dst []string) {
+	if tx.t != nil {
+		tx.t.Append(src, dst)
+	}
+}
+
+func (tx *Tx) touch(path []string) {
+	tx.paths = append(tx.paths, append([]string{}, path...))
+}
+
+// Commit keeps the changes made during the transaction
+func (tx *Tx) Commit() error {
+	if tx.t == nil {
+		return errTxDone
+	}
+	if tx.outer != nil {
+		tx.outer.paths = append(tx.outer.paths, tx.paths...)
+	}
+	tx.t.tx = tx.outer
+	tx.t, tx.root, tx.c, tx.paths, tx.outer = nil, nil, nil, nil, nil
+	return nil
+}
+
+// Rollback restores the tree to its state when the transaction began
+func (tx *Tx) Rollback() error {
+	if tx.t == nil {
+		return errTxDone
+	}
+	t, root := tx.t, tx.root
+	t.tx = tx.outer
+	t.observe(func() { t.root = root }, tx.paths...)
+	tx.t, tx.root, tx.c, tx.paths, tx.outer = nil, nil, nil, nil, nil
+	return nil
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTx(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   map[string]interface{}
+		ops  func(tr *Tree, tx *Tx)
+		out  interface{}
+	}{
+		{
+			name: "add",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"b": 1, "c": map[string]interface{}{"d": true}},
+					map[string]interface{}{"b": 2, "c": map[string]interface{}{"d": false}},
+				},
+				"e": map[string]interface{}{"f": "g", "h": []interface{}{1, 2}},
+				"i": 42,
+			},
+			ops: func(_ *Tree, tx *Tx) {
+				tx.Add([]string{"a", "0", "x"}, 1)
+				tx.Add([]string{"e"}, map[string]interface{}{"h": []interface{}{3}})
+			},
+			out: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"b": 1, "c": map[string]interface{}{"d": true}, "x": 1},
+					map[string]interface{}{"b": 2, "c": map[string]interface{}{"d": false}},
+				},
+				"e": map[string]interface{}{"f": "g", "h": []interface{}{3, 2}},
+				"i": 42,
+			},
+		},
+		{
+			name: "pipeline",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"b": 1, "c": map[string]interface{}{"d": true}},
+					map[string]interface{}{"b": 2, "c": map[string]interface{}{"d": false}},
+				},
+				"e": map[string]interface{}{"f": "g", "h": []interface{}{1, 2}},
+				"i": 42,
+			},
+			ops: func(_ *Tree, tx *Tx) {
+				tx.Move([]string{"a", "*", "c", "d"}, []string{"a", "*", "d"})
+				tx.Del([]string{"a", "*", "c"})
+				tx.Append([]string{"e", "h"}, []string{"a"})
+				tx.Move([]string{"i"}, []string{"e", "i"})
+			},
+			out: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"b": 1, "d": true},
+					map[string]interface{}{"b": 2, "d": false},
+					1,
+					2,
+				},
+				"e": map[string]interface{}{"f": "g", "i": 42},
+			},
+		},
+		{
+			name: "direct",
+			in: map[string]interface{}{
+				"a": map[string]interface{}{"x": 1, "y": []interface{}{3, 1, 2}},
+				"b": []interface{}{1, 2, 3},
+				"d": map[string]interface{}{"e": "f"},
+			},
+			ops: func(tr *Tree, tx *Tx) {
+				tx.Add([]string{"c"}, 1)
+				tr.Set([]string{"a", "x"}, 100)
+				tr.InsertAt([]string{"b"}, 0, 9)
+				tr.Pop([]string{"b"})
+				tr.SortBy([]string{"a", "y"}, func(a, b Entry) bool {
+					return compareValues(a.Get(nil), b.Get(nil)) < 0
+				})
+				tr.Walk(func(_ []string, v interface{}, _ bool) WalkAction {
+					if v == "f" {
+						return Replace("g")
+					}
+					return Continue
+				})
+			},
+			out: map[string]interface{}{
+				"a": map[string]interface{}{"x": 100, "y": []interface{}{1, 2, 3}},
+				"b": []interface{}{9, 1, 2},
+				"c": 1,
+				"d": map[string]interface{}{"e": "g"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr, _ := New(tc.in)
+			tx := tr.Begin()
+			tc.ops(tr, tx)
+			if v := tr.Get([]string{}); !reflect.DeepEqual(v, tc.out) {
+				t.Errorf("unexpected result: %v", v)
+			}
+			if err := tx.Rollback(); err != nil {
+				t.Error(err)
+			}
+			if v := tr.Get([]string{}); !reflect.DeepEqual(v, tc.in) {
+				t.Errorf("unexpected result after rolling back: %v", v)
+			}
+
+			tx = tr.Begin()
+			tc.ops(tr, tx)
+			if err := tx.Commit(); err != nil {
+				t.Error(err)
+			}
+			if v := tr.Get([]string{}); !reflect.DeepEqual(v, tc.out) {
+				t.Errorf("unexpected result after committing: %v", v)
+			}
+		})
+	}
+}
+
+func TestTx_done(t *testing.T) {
+	tr, _ := New(map[string]interface{}{"a": 1})
+	tx := tr.Begin()
+	tx.Add([]string{"b"}, 2)
+	if err := tx.Commit(); err != nil {
+		t.Error(err)
+	}
+
+	if err := tx.Commit(); err != errTxDone {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := tx.Rollback(); err != errTxDone {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	tx.Del([]string{"a"})
+	if v := tr.Get([]string{}); !reflect.DeepEqual(v, map[string]interface{}{"a": 1, "b": 2}) {
+		t.Errorf("unexpected result: %v", v)
+	}
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+// WalkFunc is called for every node visited by Walk and WalkPostOrder. The
+// value is nil for the nodes with children, and the path is only valid until
+// the function returns.
+type WalkFunc func(path []string, value interface{}, isCollection bool) WalkAction
+
+// WalkAction tells the walker how to proceed after visiting a node
+type WalkAction struct {
+	op    walkOp
+	value interface{}
+}
+
+type walkOp int
+
+const (
+	walkContinue walkOp = iota
+	walkSkip
+	walkStop
+	walkReplace
+	walkDelete
+)
+
+var (
+	// Continue keeps walking the tree
+	Continue = WalkAction{}
+	// SkipSubtree does not visit the children of the current node. It
+	// behaves like Continue when walking in post-order.
+	SkipSubtree = WalkAction{op: walkSkip}
+	// Stop ends the walk
+	Stop = WalkAction{op: walkStop}
+	// Delete removes the current node from the tree
+	Delete = WalkAction{op: walkDelete}
+)
+
+// Replace replaces the current node with v. The children of the new node are
+// not visited.
+func Replace(v interface{}) WalkAction {
+	return WalkAction{op: walkReplace, value: v}
+}
+
+// Walk visits every node of the tree but the root in pre-order, so parents
+// are visited before their children
+func (t *Tree) Walk(fn WalkFunc) {
+	if t.tx != nil {
+		t.tx.c.all(t.root)
+	}
+	o := &observer{t: t}
+	t.root.walk(make([]string, 0, 8), fn, false, o)
+	o.report()
+}
+
+// WalkPostOrder visits every node of the tree but the root in post-order, so
+// parents are visited after their children
+func (t *Tree) WalkPostOrder(fn WalkFunc) {
+	if t.tx != nil {
+		t.tx.c.all(t.root)
+	}
+	o := &observer{t: t}
+	t.root.walk(make([]string, 0, 8), fn, true, o)
+	o.report()
+}
+
+// walk visits the children of the node and reports if the walk must stop.
+// The observer is told about the nodes replaced or deleted, so read-only
+// walks do not compare the subscribed values.
+func (n *node) walk(path []string, fn WalkFunc, postOrder bool, o *observer) bool {
+	for i := 0; i < len(n.edges); {
+		e := n.edges[i]
+		p := append(path, e.label)
+
+		if !postOrder {
+			a := e.n.visit(p, fn)
+			if a.op == walkContinue {
+				if e.n.walk(p, fn, postOrder, o) {
+					return true
+				}
+				i++
+				continue
+			}
+			next, stop := n.apply(i, a, p, o)
+			if stop {
+				return true
+			}
+			i = next
+			continue
+		}
+
+		if e.n.walk(p, fn, postOrder, o) {
+			return true
+		}
+		next, stop := n.apply(i, e.n.visit(p, fn), p, o)
+		if stop {
+			return true
+		}
+		i = next
+	}
+	return false
+}
+
+func (n *node) visit(path []string, fn WalkFunc) WalkAction {
+	var v interface{}
+	if n.IsLeaf() {
+		v = n.Value
+	}
+	return fn(path, v, n.isCollection)
+}
+
+// apply executes the action over the child in the position i, found at the
+// given path, and returns the position of the next child to visit
+func (n *node) apply(i int, a WalkAction, path []string, o *observer) (int, bool) {
+	switch a.op {
+	case walkStop:
+		return i, true
+	case walkDelete:
+		o.touch(path)
+		n.removeEdges(i, i+1)
+		return i, false
+	case walkReplace:
+		o.touch(path)
+		n.edges[i].n.set(a.value)
+	}
+	return i + 1, false
+}
@@ -0,0 +1,240 @@
+/*
+ * Copyright (c) 2021 Huy Duc Dao
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tree
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTree_Walk(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		in        map[string]interface{}
+		postOrder bool
+		fn        func(path []string, v interface{}) WalkAction
+		visited   []string
+		out       string
+	}{
+		{
+			name: "pre_order",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"password": "secret", "user": "x"},
+					map[string]interface{}{"password": "secret", "user": "y"},
+				},
+				"b": map[string]interface{}{"c": 1},
+				"d": true,
+			},
+			fn: func(_ []string, _ interface{}) WalkAction { return Continue },
+			visited: []string{
+				"a []", "a.0", "a.0.password secret", "a.0.user x", "a.1", "a.1.password secret",
+				"a.1.user y", "b", "b.c 1", "d true",
+			},
+		},
+		{
+			name: "post_order",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"password": "secret", "user": "x"},
+					map[string]interface{}{"password": "secret", "user": "y"},
+				},
+				"b": map[string]interface{}{"c": 1},
+				"d": true,
+			},
+			postOrder: true,
+			fn:        func(_ []string, _ interface{}) WalkAction { return Continue },
+			visited: []string{
+				"a.0.password secret", "a.0.user x", "a.0", "a.1.password secret", "a.1.user y", "a.1",
+				"a []", "b.c 1", "b", "d true",
+			},
+		},
+		{
+			name: "skip",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"password": "secret", "user": "x"},
+					map[string]interface{}{"password": "secret", "user": "y"},
+				},
+				"b": map[string]interface{}{"c": 1},
+				"d": true,
+			},
+			fn: func(path []string, _ interface{}) WalkAction {
+				if path[0] == "a" {
+					return SkipSubtree
+				}
+				return Continue
+			},
+			visited: []string{"a []", "b", "b.c 1", "d true"},
+		},
+		{
+			name: "stop",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"password": "secret", "user": "x"},
+					map[string]interface{}{"password": "secret", "user": "y"},
+				},
+				"b": map[string]interface{}{"c": 1},
+				"d": true,
+			},
+			fn: func(path []string, _ interface{}) WalkAction {
+				if path[len(path)-1] == "user" {
+					return Stop
+				}
+				return Continue
+			},
+			visited: []string{"a []", "a.0", "a.0.password secret", "a.0.user x"},
+		},
+		{
+			name: "delete",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"password": "secret", "user": "x"},
+					map[string]interface{}{"password": "secret", "user": "y"},
+				},
+				"b": map[string]interface{}{"c": 1},
+				"d": true,
+			},
+			fn: func(path []string, _ interface{}) WalkAction {
+				if path[len(path)-1] == "password" {
+					return Delete
+				}
+				return Continue
+			},
+			visited: []string{
+				"a []", "a.0", "a.0.password secret", "a.0.user x", "a.1", "a.1.password secret",
+				"a.1.user y", "b", "b.c 1", "d true",
+			},
+			out: `
+├── a []
+│   ├── 0
+│   │   └── user	x
+│   └── 1
+│       └── user	y
+├── b
+│   └── c	1
+└── d	true
+`,
+		},
+		{
+			name: "delete_elements",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"password": "secret", "user": "x"},
+					map[string]interface{}{"password": "secret", "user": "y"},
+				},
+				"b": map[string]interface{}{"c": 1},
+				"d": true,
+			},
+			postOrder: true,
+			fn: func() func([]string, interface{}) WalkAction {
+				deleted := false
+				return func(path []string, v interface{}) WalkAction {
+					if v == "x" {
+						return Delete
+					}
+					if !deleted && len(path) == 2 && path[0] == "a" {
+						deleted = true
+						return Delete
+					}
+					return Continue
+				}
+			}(),
+			// the remaining element is relabeled once the first one is deleted
+			visited: []string{
+				"a.0.password secret", "a.0.user x", "a.0", "a.0.password secret", "a.0.user y", "a.0",
+				"a []", "b.c 1", "b", "d true",
+			},
+			out: `
+├── a []
+│   └── 0
+│       ├── password	secret
+│       └── user	y
+├── b
+│   └── c	1
+└── d	true
+`,
+		},
+		{
+			name: "replace",
+			in: map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"password": "secret", "user": "x"},
+					map[string]interface{}{"password": "secret", "user": "y"},
+				},
+				"b": map[string]interface{}{"c": 1},
+				"d": true,
+			},
+			fn: func(path []string, _ interface{}) WalkAction {
+				if path[0] == "b" {
+					return Replace([]interface{}{1, 2})
+				}
+				return SkipSubtree
+			},
+			visited: []string{"a []", "b", "d true"},
+			out: `
+├── a []
+│   ├── 0
+│   │   ├── password	secret
+│   │   └── user	x
+│   └── 1
+│       ├── password	secret
+│       └── user	y
+├── b []
+│   ├── 0	1
+│   └── 1	2
+└── d	true
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr, _ := New(tc.in)
+			tr.Sort()
+
+			var visited []string
+			fn := func(path []string, v interface{}, isCollection bool) WalkAction {
+				s := strings.Join(path, ".")
+				if v != nil {
+					s += fmt.Sprintf(" %v", v)
+				}
+				if isCollection {
+					s += " []"
+				}
+				visited = append(visited, s)
+				return tc.fn(path, v)
+			}
+
+			if tc.postOrder {
+				tr.WalkPostOrder(fn)
+			} else {
+				tr.Walk(fn)
+			}
+
+			if !reflect.DeepEqual(visited, tc.visited) {
+				t.Errorf("unexpected visits:\nhave: %v\nwant: %v", visited, tc.visited)
+			}
+			if tc.out == "" {
+				return
+			}
+			if out := "\n" + tr.String(); out != tc.out {
+				t.Errorf("unexpected result:\nhave:%s\nwant:%s", out, tc.out)
+			}
+		})
+	}
+}